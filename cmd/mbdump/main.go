@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbexport "github.com/nsvirk/gomoneybotslib/pkg/export"
+)
+
+// mbdump dumps the instruments of a segment, their option chain, or all
+// indices, to a file. The format is taken from the file extension (.csv,
+// .jsonl or .parquet).
+//
+//	go run ./cmd/mbdump -exchange NFO -segment NFO-OPT -name NIFTY -out nifty.parquet
+//	go run ./cmd/mbdump -exchange NFO -segment NFO-OPT -name NIFTY -chain -out nifty_chain.csv
+//	go run ./cmd/mbdump -indices -out indices.csv
+func main() {
+	var (
+		exchange       = flag.String("exchange", "", "exchange of the instruments, e.g. NFO")
+		segment        = flag.String("segment", "", "segment of the instruments, e.g. NFO-OPT")
+		name           = flag.String("name", "", "name of the instruments, e.g. NIFTY")
+		expiry         = flag.String("expiry", "", "expiry of the instruments, e.g. 2024-10-31")
+		instrumentType = flag.String("type", "", "instrument type, e.g. FUT, CE or PE")
+		chain          = flag.Bool("chain", false, "dump the option chain, one row per strike with CE and PE side by side")
+		indices        = flag.Bool("indices", false, "dump all indices instead of instruments")
+		out            = flag.String("out", "", "output file (.csv, .jsonl or .parquet)")
+	)
+	flag.Parse()
+
+	if *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if _, err := mbexport.FormatFromPath(*out); err != nil {
+		log.Fatalf("Invalid output file: %v", err)
+	}
+
+	// Initialize connect client
+	mbClient := mbconnect.New(os.Getenv("KITE_USER_ID"))
+	if _, err := mbClient.GenerateUserSession(os.Getenv("KITE_PASSWORD"), os.Getenv("KITE_TOTP_SECRET")); err != nil {
+		log.Fatalf("Failed to generate user session: %v", err)
+	}
+
+	if *indices {
+		allIndices, err := mbClient.IndicesAll()
+		if err != nil {
+			log.Fatalf("Failed to get indices: %v", err)
+		}
+		if err := mbexport.ExportIndices(*out, allIndices); err != nil {
+			log.Fatalf("Failed to export indices: %v", err)
+		}
+		fmt.Printf("Dumped indices of %d exchanges to %s\n", len(allIndices), *out)
+		return
+	}
+
+	if *exchange == "" && *segment == "" {
+		log.Fatal("One of -exchange or -segment is required")
+	}
+	instruments, err := mbClient.InstrumentsQuery(mbconnect.InstrumentsQueryParams{
+		Exchange:       *exchange,
+		Segment:        *segment,
+		Name:           *name,
+		Expiry:         *expiry,
+		InstrumentType: *instrumentType,
+	})
+	if err != nil {
+		log.Fatalf("Failed to query instruments: %v", err)
+	}
+	if *chain {
+		if err := mbexport.ExportOptionChain(*out, instruments); err != nil {
+			log.Fatalf("Failed to export option chain: %v", err)
+		}
+		fmt.Printf("Dumped the option chain of %d instruments to %s\n", len(instruments), *out)
+		return
+	}
+	if err := mbexport.ExportInstruments(*out, instruments); err != nil {
+		log.Fatalf("Failed to export instruments: %v", err)
+	}
+	fmt.Printf("Dumped %d instruments to %s\n", len(instruments), *out)
+}
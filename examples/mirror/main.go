@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nsvirk/gomoneybotslib/internal/database"
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbmirror "github.com/nsvirk/gomoneybotslib/pkg/mirror"
+)
+
+func main() {
+	// Configuration
+	config := struct {
+		DSN                  string
+		Schema               string
+		InstrumentsTableName string
+		IndicesTableName     string
+		LogLevel             string
+		UserID               string
+		Password             string
+		TotpSecret           string
+	}{
+		DSN:                  os.Getenv("POSTGRES_DSN"),
+		Schema:               "bots",
+		InstrumentsTableName: "instruments",
+		IndicesTableName:     "indices",
+		LogLevel:             "error",
+		UserID:               os.Getenv("KITE_USER_ID"),
+		Password:             os.Getenv("KITE_PASSWORD"),
+		TotpSecret:           os.Getenv("KITE_TOTP_SECRET"),
+	}
+
+	// Initialize Postgres connection
+	db, err := database.ConnectPostgres(config.DSN, config.Schema, config.LogLevel)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		if err := database.ClosePostgres(db); err != nil {
+			log.Printf("Error closing database connection: %v", err)
+		}
+	}()
+
+	// Initialize mirror service
+	mirrorParams := mbmirror.MirrorParams{
+		SchemaName:           config.Schema,
+		InstrumentsTableName: config.InstrumentsTableName,
+		IndicesTableName:     config.IndicesTableName,
+	}
+
+	mirrorService, err := mbmirror.NewMirrorService(mirrorParams, db)
+	if err != nil {
+		log.Fatalf("Failed to create mirror service: %v", err)
+	}
+
+	// Initialize connect client
+	mbClient := mbconnect.New(config.UserID)
+	if _, err := mbClient.GenerateUserSession(config.Password, config.TotpSecret); err != nil {
+		log.Fatalf("Failed to generate user session: %v", err)
+	}
+
+	// Sync instruments of each exchange, pruning expired contracts
+	for _, exchange := range []string{"NSE", "BSE", "NFO", "BFO"} {
+		count, err := mirrorService.SyncInstruments(mbClient, mbconnect.InstrumentsQueryParams{Exchange: exchange}, true)
+		if err != nil {
+			log.Fatalf("Failed to sync %s instruments: %v", exchange, err)
+		}
+		fmt.Printf("Synced %d %s instruments\n", count, exchange)
+	}
+
+	// Sync indices
+	count, err := mirrorService.SyncIndices(mbClient)
+	if err != nil {
+		log.Fatalf("Failed to sync indices: %v", err)
+	}
+	fmt.Printf("Synced %d indices\n", count)
+}
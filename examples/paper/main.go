@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nsvirk/gomoneybotslib/internal/database"
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbpaper "github.com/nsvirk/gomoneybotslib/pkg/paper"
+	mbstate "github.com/nsvirk/gomoneybotslib/pkg/state"
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+)
+
+func main() {
+	// Configuration
+	config := struct {
+		DSN       string
+		Schema    string
+		TableName string
+		LogLevel  string
+		UserID    string
+		BotID     string
+	}{
+		DSN:       os.Getenv("POSTGRES_DSN"),
+		Schema:    "bots",
+		TableName: "state",
+		LogLevel:  "error",
+		UserID:    "SA0123",
+		BotID:     "BOTv1",
+	}
+
+	// Initialize Postgres connection
+	db, err := database.ConnectPostgres(config.DSN, config.Schema, config.LogLevel)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		if err := database.ClosePostgres(db); err != nil {
+			log.Printf("Error closing database connection: %v", err)
+		}
+	}()
+
+	// Initialize state service, the paper book is persisted in it
+	stateService, err := mbstate.NewStateService(mbstate.StateParams{
+		UserID:     config.UserID,
+		BotID:      config.BotID,
+		SchemaName: config.Schema,
+		TableName:  config.TableName,
+	}, db)
+	if err != nil {
+		log.Fatalf("Failed to create state service: %v", err)
+	}
+
+	// Initialize the paper broker, filling against the market depth with 2 bps slippage
+	broker, err := mbpaper.New(mbpaper.PaperParams{
+		FillModel:   mbpaper.FillDepth,
+		SlippageBps: 2,
+	}, nil, stateService)
+	if err != nil {
+		log.Fatalf("Failed to create paper broker: %v", err)
+	}
+	broker.OnOrderUpdate(func(order mbconnect.Order) {
+		fmt.Printf("Order update: %s %s %d/%d @ %s\n", order.OrderID, order.Status, order.FilledQuantity, order.Quantity, order.AveragePrice)
+	})
+
+	// Register the instrument and feed it a tick, in a bot use `ticker.OnTick(broker.UpdateTick)`
+	broker.SetInstrument(mbconnect.Instrument{
+		InstrumentToken: 779521,
+		Exchange:        "NSE",
+		Tradingsymbol:   "SBIN",
+		TickSize:        0.05,
+		LotSize:         1,
+	})
+	broker.UpdateTick(mbticker.Tick{
+		Mode:            mbticker.ModeFull,
+		InstrumentToken: 779521,
+		LastPrice:       812.35,
+		Depth: mbconnect.Depth{
+			Buy:  []mbconnect.DepthItem{{Price: 812.30, Quantity: 200}},
+			Sell: []mbconnect.DepthItem{{Price: 812.40, Quantity: 50}, {Price: 812.45, Quantity: 300}},
+		},
+	})
+
+	// Place a market order, it fills across two depth levels
+	resp, err := broker.PlaceOrder(mbconnect.VarietyRegular, mbconnect.OrderParams{
+		Exchange:        "NSE",
+		Tradingsymbol:   "SBIN",
+		TransactionType: mbconnect.TransactionTypeBuy,
+		Quantity:        100,
+		Product:         mbconnect.ProductMIS,
+		OrderType:       mbconnect.OrderTypeMarket,
+	})
+	if err != nil {
+		log.Fatalf("Failed to place order: %v", err)
+	}
+	fmt.Printf("Placed order: %s\n", resp.OrderID)
+
+	positions, err := broker.Positions()
+	if err != nil {
+		log.Fatalf("Failed to get positions: %v", err)
+	}
+	for _, position := range positions.Net {
+		fmt.Printf("Position: %s %d @ %s, P&L %s\n", position.Tradingsymbol, position.Quantity, position.AveragePrice, position.PnL)
+	}
+}
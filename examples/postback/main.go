@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http/httptest"
+
+	mbpostback "github.com/nsvirk/gomoneybotslib/pkg/postback"
+	"github.com/nsvirk/gomoneybotslib/pkg/postback/postbacktest"
+)
+
+// This example serves the postback handler on a local test server and posts
+// signed sample payloads to it. In production mount the handler on the
+// postback url registered with the broker.
+func main() {
+	const secret = "api_secret"
+
+	handler, err := mbpostback.New(secret)
+	if err != nil {
+		log.Fatalf("Failed to create postback handler: %v", err)
+	}
+	handler.OnOrderUpdate(func(update mbpostback.OrderUpdate) {
+		fmt.Printf("Order update: %s %s %s %d/%d\n", update.OrderID, update.Tradingsymbol, update.Status, update.FilledQuantity, update.Quantity)
+	})
+	handler.OnError(func(err error) {
+		fmt.Printf("Rejected postback: %v\n", err)
+	})
+	updates := handler.Updates(10)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// A correctly signed postback is accepted
+	status, err := postbacktest.Post(server.URL, postbacktest.SampleUpdate("151220000000000"), secret)
+	if err != nil {
+		log.Fatalf("Failed to post: %v", err)
+	}
+	fmt.Printf("Signed postback: %d\n", status)
+	update := <-updates
+	fmt.Printf("Received on channel: %s\n", update.OrderID)
+
+	// A postback signed with another secret is rejected
+	status, err = postbacktest.Post(server.URL, postbacktest.SampleUpdate("151220000000001"), "wrong_secret")
+	if err != nil {
+		log.Fatalf("Failed to post: %v", err)
+	}
+	fmt.Printf("Forged postback: %d\n", status)
+
+	handler.Close()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	mbrecorder "github.com/nsvirk/gomoneybotslib/pkg/recorder"
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+	"github.com/nsvirk/gomoneybotslib/pkg/ticker/tickertest"
+)
+
+// This example records ticks from a local test server and replays them.
+func main() {
+	path := filepath.Join(os.TempDir(), "ticks.mbtk")
+	os.Remove(path)
+
+	server := tickertest.NewServer("enctoken")
+	defer server.Close()
+
+	ticker := mbticker.NewWithCredentials("SA0123", "enctoken")
+	ticker.SetRootURL(server.URL())
+
+	recorder, err := mbrecorder.NewRecorder(path)
+	if err != nil {
+		log.Fatalf("Failed to create recorder: %v", err)
+	}
+	ticker.OnTick(recorder.RecordTick)
+
+	connected := make(chan struct{}, 1)
+	ticker.OnConnect(func() { connected <- struct{}{} })
+	ticks := ticker.Ticks(100)
+	go ticker.Serve()
+	<-connected
+
+	for i := 0; i < 3; i++ {
+		server.SendTicks([]mbticker.Tick{
+			{Mode: mbticker.ModeLTP, InstrumentToken: 779521, LastPrice: 812.35 + float64(i)*0.05},
+		})
+		<-ticks
+		time.Sleep(200 * time.Millisecond)
+	}
+	ticker.Stop()
+	if err := recorder.Close(); err != nil {
+		log.Fatalf("Failed to close recorder: %v", err)
+	}
+
+	// Replay at 4x speed through the same interface as the live ticker
+	var source mbticker.Source
+	replayer, err := mbrecorder.NewFileReplayer(path, 4)
+	if err != nil {
+		log.Fatalf("Failed to create replayer: %v", err)
+	}
+	replayer.OnError(func(err error) {
+		log.Printf("Replay error: %v", err)
+	})
+	source = replayer
+
+	start := time.Now()
+	source.OnTick(func(tick mbticker.Tick) {
+		fmt.Printf("Replayed +%v: %d %s %.2f\n", time.Since(start).Round(10*time.Millisecond), tick.InstrumentToken, tick.Mode, tick.LastPrice)
+	})
+	source.Serve()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/nsvirk/gomoneybotslib/internal/database"
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbsnapshot "github.com/nsvirk/gomoneybotslib/pkg/snapshot"
+)
+
+func main() {
+	// Configuration
+	config := struct {
+		DSN        string
+		Schema     string
+		TableName  string
+		LogLevel   string
+		UserID     string
+		Password   string
+		TotpSecret string
+	}{
+		DSN:        os.Getenv("POSTGRES_DSN"),
+		Schema:     "bots",
+		TableName:  "instrument_snapshots",
+		LogLevel:   "error",
+		UserID:     os.Getenv("KITE_USER_ID"),
+		Password:   os.Getenv("KITE_PASSWORD"),
+		TotpSecret: os.Getenv("KITE_TOTP_SECRET"),
+	}
+
+	// Initialize Postgres connection
+	db, err := database.ConnectPostgres(config.DSN, config.Schema, config.LogLevel)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		if err := database.ClosePostgres(db); err != nil {
+			log.Printf("Error closing database connection: %v", err)
+		}
+	}()
+
+	// Initialize snapshot service
+	snapshotParams := mbsnapshot.SnapshotParams{
+		SchemaName: config.Schema,
+		TableName:  config.TableName,
+	}
+
+	snapshotService, err := mbsnapshot.NewSnapshotService(snapshotParams, db)
+	if err != nil {
+		log.Fatalf("Failed to create snapshot service: %v", err)
+	}
+
+	// Initialize connect client
+	mbClient := mbconnect.New(config.UserID)
+	if _, err := mbClient.GenerateUserSession(config.Password, config.TotpSecret); err != nil {
+		log.Fatalf("Failed to generate user session: %v", err)
+	}
+
+	// Capture today's NFO snapshot and diff against the previous one
+	report, err := snapshotService.Capture(mbClient, mbconnect.InstrumentsQueryParams{Exchange: "NFO"}, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to capture snapshot: %v", err)
+	}
+	if report == nil {
+		fmt.Println("First snapshot captured, nothing to compare")
+		return
+	}
+
+	fmt.Printf("Added: %d, Removed: %d, Lot size changes: %d, Tick size changes: %d\n",
+		len(report.Added), len(report.Removed), len(report.LotSizeChanges), len(report.TickSizeChanges))
+	for _, change := range report.LotSizeChanges {
+		fmt.Printf("  %s:%s lot size %v -> %v\n", change.Exchange, change.Tradingsymbol, change.Old, change.New)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+	"github.com/nsvirk/gomoneybotslib/pkg/ticker/tickertest"
+)
+
+// This example runs against a local test server, so it needs no credentials.
+// With a live session use `mbticker.New(mbClient)` and skip `SetRootURL`.
+func main() {
+	server := tickertest.NewServer("enctoken")
+	defer server.Close()
+
+	ticker := mbticker.NewWithCredentials("SA0123", "enctoken")
+	ticker.SetRootURL(server.URL())
+
+	connected := make(chan struct{}, 1)
+	ticker.OnConnect(func() {
+		fmt.Println("Connected")
+		connected <- struct{}{}
+	})
+	ticker.OnError(func(err error) {
+		fmt.Printf("Error: %v\n", err)
+	})
+	ticker.OnClose(func(code int, reason string) {
+		fmt.Printf("Closed: %d %s\n", code, reason)
+	})
+	ticker.OnReconnect(func(attempt int, delay time.Duration) {
+		fmt.Printf("Reconnecting: attempt %d in %v\n", attempt, delay)
+	})
+
+	ticks := ticker.Ticks(100)
+	go ticker.Serve()
+	<-connected
+
+	// Subscribe NIFTY 50 in full mode and SBIN in ltp mode
+	if err := ticker.Subscribe([]uint32{256265, 779521}); err != nil {
+		log.Fatalf("Failed to subscribe: %v", err)
+	}
+	if err := ticker.SetMode(mbticker.ModeFull, []uint32{256265}); err != nil {
+		log.Fatalf("Failed to set mode: %v", err)
+	}
+	if err := ticker.SetMode(mbticker.ModeLTP, []uint32{779521}); err != nil {
+		log.Fatalf("Failed to set mode: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	fmt.Printf("Server subscriptions: %v\n", server.Subscriptions())
+
+	server.SendTicks([]mbticker.Tick{
+		{Mode: mbticker.ModeFull, InstrumentToken: 256265, LastPrice: 24854.05, NetChange: 104.2, Timestamp: time.Now()},
+		{Mode: mbticker.ModeLTP, InstrumentToken: 779521, LastPrice: 812.35},
+	})
+	for i := 0; i < 2; i++ {
+		tick := <-ticks
+		fmt.Printf("Tick: %d %s %.2f\n", tick.InstrumentToken, tick.Mode, tick.LastPrice)
+	}
+
+	// Drop the connection, the ticker reconnects and resubscribes
+	server.DropConnections()
+	<-connected
+	time.Sleep(100 * time.Millisecond)
+	fmt.Printf("Server subscriptions after reconnect: %v\n", server.Subscriptions())
+
+	ticker.Stop()
+}
@@ -0,0 +1,51 @@
+package mbbroker
+
+import (
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+)
+
+// Executor places orders and reports their outcome
+type Executor interface {
+	PlaceOrder(variety string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error)
+	ModifyOrder(variety, orderID string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error)
+	CancelOrder(variety, orderID string) (mbconnect.OrderResponse, error)
+	ExitOrder(variety, orderID, parentOrderID string) (mbconnect.OrderResponse, error)
+	Orders() ([]mbconnect.Order, error)
+	OrderHistory(orderID string) ([]mbconnect.Order, error)
+	Trades() ([]mbconnect.Trade, error)
+	OrderTrades(orderID string) ([]mbconnect.Trade, error)
+	Positions() (mbconnect.Positions, error)
+}
+
+// MarketData provides quotes, instruments and account margins
+type MarketData interface {
+	Margins() (mbconnect.AllMargins, error)
+	Quote(instruments []string) (map[string]mbconnect.Quote, error)
+	LTP(instruments []string) (map[string]mbconnect.QuoteLTP, error)
+	InstrumentsInfoBySymbols(symbols []string) (map[string]mbconnect.Instrument, error)
+	InstrumentsQuery(qp mbconnect.InstrumentsQueryParams) ([]mbconnect.Instrument, error)
+}
+
+// Broker is everything a strategy needs from a broker. Strategies that depend
+// on it run unchanged against the live client, a paper broker or a backtest.
+type Broker interface {
+	Executor
+	MarketData
+}
+
+var _ Broker = (*mbconnect.Client)(nil)
+
+// composite routes orders to an executor and everything else to market data
+type composite struct {
+	Executor
+	MarketData
+}
+
+// WithExecutor returns a broker that places orders through the executor and
+// gets quotes, instruments and margins from the market data, e.g. a paper
+// broker over the live client:
+//
+//	broker := mbbroker.WithExecutor(client, paperBroker)
+func WithExecutor(marketData MarketData, executor Executor) Broker {
+	return composite{Executor: executor, MarketData: marketData}
+}
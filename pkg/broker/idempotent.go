@@ -0,0 +1,288 @@
+package mbbroker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbstate "github.com/nsvirk/gomoneybotslib/pkg/state"
+	"gorm.io/gorm"
+)
+
+// Defaults of the idempotent placer
+const (
+	defaultIntentKeyPrefix = "order_intent:"
+	defaultRetries         = 2
+	defaultReconcileDelay  = time.Second
+	clientTagPrefix        = "mb"
+	clientTagHashLength    = 16
+)
+
+// Intent statuses
+const (
+	IntentPending = "pending"
+	IntentPlaced  = "placed"
+	IntentFailed  = "failed"
+)
+
+// StateStore persists order intents, `*mbstate.StateService` satisfies it
+type StateStore interface {
+	Get(key string) (string, map[string]interface{}, error)
+	Set(key, value string, meta map[string]interface{}) error
+}
+
+var _ StateStore = (*mbstate.StateService)(nil)
+
+// IdempotentParams are the parameters for the idempotent placer
+type IdempotentParams struct {
+	// Retries is the number of resends after an ambiguous failure, defaults to 2
+	Retries int
+	// ReconcileDelay is the wait before checking the order book after an
+	// ambiguous failure, so the order has time to show up. Defaults to 1s.
+	ReconcileDelay time.Duration
+	// KeyPrefix is the prefix of the intent keys in the state store, defaults to `order_intent:`
+	KeyPrefix string
+}
+
+// OrderIntent is the persisted record of an order placement
+type OrderIntent struct {
+	ClientOrderID string                `json:"client_order_id"`
+	Tag           string                `json:"tag"`
+	Variety       string                `json:"variety"`
+	Params        mbconnect.OrderParams `json:"params"`
+	Status        string                `json:"status"`
+	OrderID       string                `json:"order_id,omitempty"`
+	Attempts      int                   `json:"attempts"`
+	Error         string                `json:"error,omitempty"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+// IdempotentPlacer places each client order at most once. The order is stamped
+// with a tag derived from the client order id and its intent is recorded
+// before it is sent. When a placement fails ambiguously, e.g. a timeout, the
+// order book is checked for the tag before the order is resent. Placing the
+// same client order id again, even after a restart, returns the original order.
+type IdempotentPlacer struct {
+	executor Executor
+	state    StateStore
+	params   IdempotentParams
+
+	mu    sync.Mutex
+	locks map[string]*intentLock
+}
+
+// intentLock serializes placements of a client order id, it is removed from
+// the placer once no placement holds or waits for it
+type intentLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewIdempotentPlacer creates a new idempotent placer
+func NewIdempotentPlacer(executor Executor, state StateStore, params IdempotentParams) *IdempotentPlacer {
+	if params.Retries <= 0 {
+		params.Retries = defaultRetries
+	}
+	if params.ReconcileDelay <= 0 {
+		params.ReconcileDelay = defaultReconcileDelay
+	}
+	if params.KeyPrefix == "" {
+		params.KeyPrefix = defaultIntentKeyPrefix
+	}
+	return &IdempotentPlacer{
+		executor: executor,
+		state:    state,
+		params:   params,
+		locks:    make(map[string]*intentLock),
+	}
+}
+
+// ClientTag returns the order tag of a client order id
+func ClientTag(clientOrderID string) string {
+	sum := sha256.Sum256([]byte(clientOrderID))
+	return clientTagPrefix + hex.EncodeToString(sum[:])[:clientTagHashLength]
+}
+
+// PlaceOrder places the order once for the client order id. The order's tag is
+// replaced by the client tag.
+func (p *IdempotentPlacer) PlaceOrder(clientOrderID, variety string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error) {
+	return p.PlaceOrderWithContext(context.Background(), clientOrderID, variety, params)
+}
+
+// PlaceOrderWithContext places the order once for the client order id. The
+// wait before reconciling an ambiguous failure ends when the context is
+// cancelled, leaving the intent pending so placing again reconciles first.
+func (p *IdempotentPlacer) PlaceOrderWithContext(ctx context.Context, clientOrderID, variety string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error) {
+	if clientOrderID == "" {
+		return mbconnect.OrderResponse{}, mbconnect.NewError(mbconnect.InputError, "`client_order_id` is required", nil)
+	}
+	params.Tag = ClientTag(clientOrderID)
+	if err := params.Validate(variety); err != nil {
+		return mbconnect.OrderResponse{}, err
+	}
+
+	p.lock(clientOrderID)
+	defer p.unlock(clientOrderID)
+
+	intent, found, err := p.Intent(clientOrderID)
+	if err != nil {
+		return mbconnect.OrderResponse{}, err
+	}
+	switch {
+	case found && intent.Status == IntentPlaced:
+		return mbconnect.OrderResponse{OrderID: intent.OrderID}, nil
+	case found && intent.Status == IntentPending:
+		// A previous attempt didn't finish, the order may have been placed.
+		orderID, err := p.reconcile(intent.Tag)
+		if err != nil {
+			return mbconnect.OrderResponse{}, err
+		}
+		if orderID != "" {
+			return p.placed(intent, orderID)
+		}
+	default:
+		intent = OrderIntent{ClientOrderID: clientOrderID, Tag: params.Tag}
+	}
+	intent.Variety = variety
+	intent.Params = params
+
+	for {
+		intent.Status = IntentPending
+		intent.Attempts++
+		if err := p.save(intent); err != nil {
+			return mbconnect.OrderResponse{}, err
+		}
+
+		resp, err := p.executor.PlaceOrder(variety, params)
+		if err == nil {
+			return p.placed(intent, resp.OrderID)
+		}
+		if !IsAmbiguous(err) {
+			intent.Status = IntentFailed
+			intent.Error = err.Error()
+			if saveErr := p.save(intent); saveErr != nil {
+				return mbconnect.OrderResponse{}, saveErr
+			}
+			return mbconnect.OrderResponse{}, err
+		}
+
+		timer := time.NewTimer(p.params.ReconcileDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return mbconnect.OrderResponse{}, fmt.Errorf("%w (reconcile cancelled: %v)", err, ctx.Err())
+		case <-timer.C:
+		}
+		orderID, reconcileErr := p.reconcile(intent.Tag)
+		if reconcileErr != nil {
+			// The intent stays pending, placing again reconciles first.
+			return mbconnect.OrderResponse{}, fmt.Errorf("%w (reconcile failed: %v)", err, reconcileErr)
+		}
+		if orderID != "" {
+			return p.placed(intent, orderID)
+		}
+		if intent.Attempts > p.params.Retries {
+			return mbconnect.OrderResponse{}, err
+		}
+	}
+}
+
+// Intent returns the recorded intent of the client order id
+func (p *IdempotentPlacer) Intent(clientOrderID string) (OrderIntent, bool, error) {
+	var intent OrderIntent
+	value, _, err := p.state.Get(p.params.KeyPrefix + clientOrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return intent, false, nil
+	}
+	if err != nil {
+		return intent, false, fmt.Errorf("failed to get order intent: %w", err)
+	}
+	if err := json.Unmarshal([]byte(value), &intent); err != nil {
+		return intent, false, fmt.Errorf("failed to decode order intent: %w", err)
+	}
+	return intent, true, nil
+}
+
+// IsAmbiguous reports whether a failed placement may still have reached the
+// exchange: network and response errors, and server errors
+func IsAmbiguous(err error) bool {
+	var apiErr mbconnect.Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	switch apiErr.ErrorType {
+	case mbconnect.NetworkError, mbconnect.DataError:
+		return true
+	}
+	return apiErr.Code >= http.StatusInternalServerError && apiErr.ErrorType == mbconnect.GeneralError
+}
+
+// reconcile returns the id of the order with the tag in the order book, empty if there is none
+func (p *IdempotentPlacer) reconcile(tag string) (string, error) {
+	orders, err := p.executor.Orders()
+	if err != nil {
+		return "", fmt.Errorf("failed to get orders: %w", err)
+	}
+	for _, order := range orders {
+		if order.Tag == tag || slices.Contains(order.Tags, tag) {
+			return order.OrderID, nil
+		}
+	}
+	return "", nil
+}
+
+// placed records the order id of the intent
+func (p *IdempotentPlacer) placed(intent OrderIntent, orderID string) (mbconnect.OrderResponse, error) {
+	intent.Status = IntentPlaced
+	intent.OrderID = orderID
+	intent.Error = ""
+	resp := mbconnect.OrderResponse{OrderID: orderID}
+	return resp, p.save(intent)
+}
+
+// save persists the intent
+func (p *IdempotentPlacer) save(intent OrderIntent) error {
+	intent.UpdatedAt = time.Now()
+	value, err := json.Marshal(intent)
+	if err != nil {
+		return fmt.Errorf("failed to encode order intent: %w", err)
+	}
+	meta := map[string]interface{}{"status": intent.Status, "order_id": intent.OrderID, "tag": intent.Tag}
+	if err := p.state.Set(p.params.KeyPrefix+intent.ClientOrderID, string(value), meta); err != nil {
+		return fmt.Errorf("failed to save order intent: %w", err)
+	}
+	return nil
+}
+
+// lock acquires the lock of the client order id
+func (p *IdempotentPlacer) lock(clientOrderID string) {
+	p.mu.Lock()
+	lock, ok := p.locks[clientOrderID]
+	if !ok {
+		lock = &intentLock{}
+		p.locks[clientOrderID] = lock
+	}
+	lock.refs++
+	p.mu.Unlock()
+	lock.mu.Lock()
+}
+
+// unlock releases the lock of the client order id, removing it when unused
+func (p *IdempotentPlacer) unlock(clientOrderID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	lock := p.locks[clientOrderID]
+	lock.mu.Unlock()
+	lock.refs--
+	if lock.refs == 0 {
+		delete(p.locks, clientOrderID)
+	}
+}
@@ -0,0 +1,259 @@
+package mbbroker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	"gorm.io/gorm"
+)
+
+// memoryStore is an in-memory state store
+type memoryStore struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: make(map[string]string)}
+}
+
+func (s *memoryStore) Get(key string) (string, map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	if !ok {
+		return "", nil, gorm.ErrRecordNotFound
+	}
+	return value, nil, nil
+}
+
+func (s *memoryStore) Set(key, value string, meta map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	return nil
+}
+
+// placeResult is the scripted outcome of a placement, an order that reached
+// the exchange shows up in the order book even when an error is returned
+type placeResult struct {
+	err     error
+	reached bool
+}
+
+// scriptedExecutor places orders with scripted outcomes, placements past the
+// script succeed
+type scriptedExecutor struct {
+	Executor
+
+	mu      sync.Mutex
+	results []placeResult
+	calls   int
+	orders  []mbconnect.Order
+}
+
+func (e *scriptedExecutor) PlaceOrder(variety string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls++
+	orderID := fmt.Sprintf("order-%d", e.calls)
+	var result placeResult
+	if e.calls <= len(e.results) {
+		result = e.results[e.calls-1]
+	}
+	if result.err == nil || result.reached {
+		e.orders = append(e.orders, mbconnect.Order{OrderID: orderID, Tag: params.Tag, Status: mbconnect.OrderStatusOpen})
+	}
+	if result.err != nil {
+		return mbconnect.OrderResponse{}, result.err
+	}
+	return mbconnect.OrderResponse{OrderID: orderID}, nil
+}
+
+func (e *scriptedExecutor) Orders() ([]mbconnect.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]mbconnect.Order(nil), e.orders...), nil
+}
+
+var (
+	errTimeout = errors.New("request timed out")
+	orderBuy   = mbconnect.OrderParams{
+		Exchange:        "NFO",
+		Tradingsymbol:   "NIFTY24OCTFUT",
+		TransactionType: mbconnect.TransactionTypeBuy,
+		Quantity:        25,
+		OrderType:       mbconnect.OrderTypeMarket,
+		Product:         mbconnect.ProductNRML,
+	}
+)
+
+// newTestPlacer creates an idempotent placer that reconciles without waiting
+func newTestPlacer(executor Executor, store StateStore) *IdempotentPlacer {
+	return NewIdempotentPlacer(executor, store, IdempotentParams{ReconcileDelay: time.Millisecond})
+}
+
+// checkIntent checks the recorded intent of the client order id
+func checkIntent(t *testing.T, p *IdempotentPlacer, clientOrderID, status, orderID string, attempts int) {
+	t.Helper()
+	intent, found, err := p.Intent(clientOrderID)
+	if err != nil || !found {
+		t.Fatalf("Intent(%q) = %v, %v, want a recorded intent", clientOrderID, found, err)
+	}
+	if intent.Status != status || intent.OrderID != orderID || intent.Attempts != attempts {
+		t.Errorf("intent = %s %q after %d attempts, want %s %q after %d attempts",
+			intent.Status, intent.OrderID, intent.Attempts, status, orderID, attempts)
+	}
+}
+
+func TestIdempotentPlacerReconcilesPendingIntent(t *testing.T) {
+	tests := []struct {
+		name        string
+		orders      []mbconnect.Order
+		wantOrderID string
+		wantCalls   int
+		wantAttempt int
+	}{
+		{"order in the book", []mbconnect.Order{{OrderID: "order-0", Tag: ClientTag("client-1")}}, "order-0", 0, 1},
+		{"order in the tags", []mbconnect.Order{{OrderID: "order-0", Tags: []string{"strategy", ClientTag("client-1")}}}, "order-0", 0, 1},
+		{"order not in the book", []mbconnect.Order{{OrderID: "order-0", Tag: ClientTag("client-2")}}, "order-1", 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The intent a placer left pending before a restart.
+			store := newMemoryStore()
+			pending, _ := json.Marshal(OrderIntent{
+				ClientOrderID: "client-1",
+				Tag:           ClientTag("client-1"),
+				Variety:       mbconnect.VarietyRegular,
+				Params:        orderBuy,
+				Status:        IntentPending,
+				Attempts:      1,
+			})
+			store.values[defaultIntentKeyPrefix+"client-1"] = string(pending)
+
+			executor := &scriptedExecutor{orders: tt.orders}
+			placer := newTestPlacer(executor, store)
+			resp, err := placer.PlaceOrder("client-1", mbconnect.VarietyRegular, orderBuy)
+			if err != nil {
+				t.Fatalf("PlaceOrder() error = %v", err)
+			}
+			if resp.OrderID != tt.wantOrderID {
+				t.Errorf("PlaceOrder() order id = %q, want %q", resp.OrderID, tt.wantOrderID)
+			}
+			if executor.calls != tt.wantCalls {
+				t.Errorf("placed %d orders, want %d", executor.calls, tt.wantCalls)
+			}
+			checkIntent(t, placer, "client-1", IntentPlaced, tt.wantOrderID, tt.wantAttempt)
+
+			// Placing again returns the recorded order.
+			resp, err = placer.PlaceOrder("client-1", mbconnect.VarietyRegular, orderBuy)
+			if err != nil || resp.OrderID != tt.wantOrderID {
+				t.Errorf("PlaceOrder() again = %q, %v, want %q", resp.OrderID, err, tt.wantOrderID)
+			}
+			if executor.calls != tt.wantCalls {
+				t.Errorf("placed %d orders after placing again, want %d", executor.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotentPlacerAmbiguousFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		results     []placeResult
+		wantErr     error
+		wantOrderID string
+		wantStatus  string
+		wantCalls   int
+	}{
+		{
+			name:        "reconcile finds the order",
+			results:     []placeResult{{err: errTimeout, reached: true}},
+			wantOrderID: "order-1",
+			wantStatus:  IntentPlaced,
+			wantCalls:   1,
+		},
+		{
+			name:        "resent after reconcile finds nothing",
+			results:     []placeResult{{err: errTimeout}},
+			wantOrderID: "order-2",
+			wantStatus:  IntentPlaced,
+			wantCalls:   2,
+		},
+		{
+			name:       "retry limit",
+			results:    []placeResult{{err: errTimeout}, {err: errTimeout}, {err: errTimeout}, {err: errTimeout}},
+			wantErr:    errTimeout,
+			wantStatus: IntentPending,
+			wantCalls:  3,
+		},
+		{
+			name:       "not ambiguous",
+			results:    []placeResult{{err: mbconnect.NewError(mbconnect.InputError, "insufficient margin", nil)}},
+			wantErr:    mbconnect.Error{},
+			wantStatus: IntentFailed,
+			wantCalls:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &scriptedExecutor{results: tt.results}
+			placer := newTestPlacer(executor, newMemoryStore())
+			resp, err := placer.PlaceOrder("client-1", mbconnect.VarietyRegular, orderBuy)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("PlaceOrder() error = %v", err)
+				}
+			case mbconnect.Error:
+				if !errors.As(err, &want) {
+					t.Fatalf("PlaceOrder() error = %v, want an API error", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("PlaceOrder() error = %v, want %v", err, want)
+				}
+			}
+			if resp.OrderID != tt.wantOrderID {
+				t.Errorf("PlaceOrder() order id = %q, want %q", resp.OrderID, tt.wantOrderID)
+			}
+			if executor.calls != tt.wantCalls {
+				t.Errorf("placed %d orders, want %d", executor.calls, tt.wantCalls)
+			}
+			checkIntent(t, placer, "client-1", tt.wantStatus, tt.wantOrderID, tt.wantCalls)
+		})
+	}
+}
+
+func TestIdempotentPlacerCancelledContext(t *testing.T) {
+	executor := &scriptedExecutor{results: []placeResult{{err: errTimeout}}}
+	placer := NewIdempotentPlacer(executor, newMemoryStore(), IdempotentParams{ReconcileDelay: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := placer.PlaceOrderWithContext(ctx, "client-1", mbconnect.VarietyRegular, orderBuy)
+	if !errors.Is(err, errTimeout) {
+		t.Fatalf("PlaceOrderWithContext() error = %v, want %v", err, errTimeout)
+	}
+	if executor.calls != 1 {
+		t.Errorf("placed %d orders, want 1", executor.calls)
+	}
+	checkIntent(t, placer, "client-1", IntentPending, "", 1)
+
+	// Placing again reconciles the pending intent before resending.
+	resp, err := placer.PlaceOrder("client-1", mbconnect.VarietyRegular, orderBuy)
+	if err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	if resp.OrderID != "order-2" {
+		t.Errorf("PlaceOrder() order id = %q, want %q", resp.OrderID, "order-2")
+	}
+	checkIntent(t, placer, "client-1", IntentPlaced, "order-2", 2)
+}
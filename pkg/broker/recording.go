@@ -0,0 +1,131 @@
+package mbbroker
+
+import (
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mblogger "github.com/nsvirk/gomoneybotslib/pkg/logger"
+)
+
+// recording is a broker that logs every call
+type recording struct {
+	broker Broker
+	logger *mblogger.LoggerService
+}
+
+// NewRecording returns a broker that logs every call to the broker, with its
+// arguments, duration and error, through the logger. Failed calls are logged
+// at the error level.
+func NewRecording(broker Broker, logger *mblogger.LoggerService) Broker {
+	return &recording{broker: broker, logger: logger}
+}
+
+// record logs a call that started at `start`
+func (r *recording) record(method string, start time.Time, err error, meta map[string]interface{}) {
+	meta["method"] = method
+	meta["duration_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		meta["error"] = err.Error()
+		r.logger.Error("broker: "+method+" failed", meta)
+		return
+	}
+	r.logger.Info("broker: "+method, meta)
+}
+
+func (r *recording) PlaceOrder(variety string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error) {
+	start := time.Now()
+	resp, err := r.broker.PlaceOrder(variety, params)
+	r.record("PlaceOrder", start, err, map[string]interface{}{"variety": variety, "params": params, "order_id": resp.OrderID})
+	return resp, err
+}
+
+func (r *recording) ModifyOrder(variety, orderID string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error) {
+	start := time.Now()
+	resp, err := r.broker.ModifyOrder(variety, orderID, params)
+	r.record("ModifyOrder", start, err, map[string]interface{}{"variety": variety, "order_id": orderID, "params": params})
+	return resp, err
+}
+
+func (r *recording) CancelOrder(variety, orderID string) (mbconnect.OrderResponse, error) {
+	start := time.Now()
+	resp, err := r.broker.CancelOrder(variety, orderID)
+	r.record("CancelOrder", start, err, map[string]interface{}{"variety": variety, "order_id": orderID})
+	return resp, err
+}
+
+func (r *recording) ExitOrder(variety, orderID, parentOrderID string) (mbconnect.OrderResponse, error) {
+	start := time.Now()
+	resp, err := r.broker.ExitOrder(variety, orderID, parentOrderID)
+	r.record("ExitOrder", start, err, map[string]interface{}{"variety": variety, "order_id": orderID, "parent_order_id": parentOrderID})
+	return resp, err
+}
+
+func (r *recording) Orders() ([]mbconnect.Order, error) {
+	start := time.Now()
+	orders, err := r.broker.Orders()
+	r.record("Orders", start, err, map[string]interface{}{"count": len(orders)})
+	return orders, err
+}
+
+func (r *recording) OrderHistory(orderID string) ([]mbconnect.Order, error) {
+	start := time.Now()
+	history, err := r.broker.OrderHistory(orderID)
+	r.record("OrderHistory", start, err, map[string]interface{}{"order_id": orderID, "count": len(history)})
+	return history, err
+}
+
+func (r *recording) Trades() ([]mbconnect.Trade, error) {
+	start := time.Now()
+	trades, err := r.broker.Trades()
+	r.record("Trades", start, err, map[string]interface{}{"count": len(trades)})
+	return trades, err
+}
+
+func (r *recording) OrderTrades(orderID string) ([]mbconnect.Trade, error) {
+	start := time.Now()
+	trades, err := r.broker.OrderTrades(orderID)
+	r.record("OrderTrades", start, err, map[string]interface{}{"order_id": orderID, "count": len(trades)})
+	return trades, err
+}
+
+func (r *recording) Positions() (mbconnect.Positions, error) {
+	start := time.Now()
+	positions, err := r.broker.Positions()
+	r.record("Positions", start, err, map[string]interface{}{"net": len(positions.Net), "day": len(positions.Day)})
+	return positions, err
+}
+
+func (r *recording) Margins() (mbconnect.AllMargins, error) {
+	start := time.Now()
+	margins, err := r.broker.Margins()
+	r.record("Margins", start, err, map[string]interface{}{})
+	return margins, err
+}
+
+func (r *recording) Quote(instruments []string) (map[string]mbconnect.Quote, error) {
+	start := time.Now()
+	quotes, err := r.broker.Quote(instruments)
+	r.record("Quote", start, err, map[string]interface{}{"instruments": instruments, "count": len(quotes)})
+	return quotes, err
+}
+
+func (r *recording) LTP(instruments []string) (map[string]mbconnect.QuoteLTP, error) {
+	start := time.Now()
+	quotes, err := r.broker.LTP(instruments)
+	r.record("LTP", start, err, map[string]interface{}{"instruments": instruments, "count": len(quotes)})
+	return quotes, err
+}
+
+func (r *recording) InstrumentsInfoBySymbols(symbols []string) (map[string]mbconnect.Instrument, error) {
+	start := time.Now()
+	instruments, err := r.broker.InstrumentsInfoBySymbols(symbols)
+	r.record("InstrumentsInfoBySymbols", start, err, map[string]interface{}{"symbols": symbols, "count": len(instruments)})
+	return instruments, err
+}
+
+func (r *recording) InstrumentsQuery(qp mbconnect.InstrumentsQueryParams) ([]mbconnect.Instrument, error) {
+	start := time.Now()
+	instruments, err := r.broker.InstrumentsQuery(qp)
+	r.record("InstrumentsQuery", start, err, map[string]interface{}{"query": qp, "count": len(instruments)})
+	return instruments, err
+}
@@ -0,0 +1,303 @@
+package mbbroker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	"github.com/shopspring/decimal"
+)
+
+// defaultPacing is the delay between child orders
+const defaultPacing = 200 * time.Millisecond
+
+// SlicerParams are the parameters for the order slicer
+type SlicerParams struct {
+	// FreezeQuantities are the exchange freeze quantities by underlying name,
+	// e.g. "NIFTY". Orders of underlyings without one are not sliced.
+	FreezeQuantities map[string]uint
+	// Pacing is the delay between child orders, defaults to 200ms
+	Pacing time.Duration
+}
+
+// Slicer splits orders above the exchange freeze quantity into child orders
+type Slicer struct {
+	executor Executor
+	params   SlicerParams
+}
+
+// ChildOrder is a struct that represents a child order of a sliced order
+type ChildOrder struct {
+	Quantity       uint
+	OrderID        string
+	Status         string
+	FilledQuantity uint
+	AveragePrice   decimal.Decimal
+	Error          string
+}
+
+// SliceStatus is a struct that represents the aggregate state of a sliced order
+type SliceStatus struct {
+	Quantity       uint
+	PlacedQuantity uint
+	FilledQuantity uint
+	AveragePrice   decimal.Decimal
+	Complete       bool
+	Children       []ChildOrder
+}
+
+// SlicedOrder is a parent order being placed as child orders
+type SlicedOrder struct {
+	executor Executor
+	variety  string
+	params   mbconnect.OrderParams
+	quantity uint
+
+	mu       sync.Mutex
+	children []ChildOrder
+	early    map[string]mbconnect.Order
+	err      error
+
+	cancel chan struct{}
+	once   sync.Once
+	done   chan struct{}
+}
+
+// NewSlicer creates a new order slicer
+func NewSlicer(executor Executor, params SlicerParams) *Slicer {
+	if params.Pacing <= 0 {
+		params.Pacing = defaultPacing
+	}
+	return &Slicer{executor: executor, params: params}
+}
+
+// Slice splits the quantity into child quantities of at most the freeze
+// quantity of the instrument's underlying, each a multiple of the lot size
+func (s *Slicer) Slice(instrument mbconnect.Instrument, quantity uint) ([]uint, error) {
+	if err := instrument.ValidateQuantity(quantity); err != nil {
+		return nil, err
+	}
+	freeze := s.params.FreezeQuantities[instrument.Name]
+	child := instrument.ClampToFreezeQuantity(quantity, freeze)
+	if child == 0 {
+		return nil, mbconnect.NewError(mbconnect.InputError, fmt.Sprintf("freeze quantity %d of %s is less than a lot", freeze, instrument.Name), nil)
+	}
+
+	var quantities []uint
+	for remaining := quantity; remaining > 0; remaining -= min(child, remaining) {
+		quantities = append(quantities, min(child, remaining))
+	}
+	return quantities, nil
+}
+
+// PlaceOrder places the order as child orders in the background, `params`
+// exchange and tradingsymbol are taken from the instrument. Use the returned
+// sliced order to track the fills, wait for placement or cancel it.
+func (s *Slicer) PlaceOrder(instrument mbconnect.Instrument, variety string, params mbconnect.OrderParams) (*SlicedOrder, error) {
+	params.Exchange = instrument.Exchange
+	params.Tradingsymbol = instrument.Tradingsymbol
+	if err := params.Validate(variety); err != nil {
+		return nil, err
+	}
+	quantities, err := s.Slice(instrument, params.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &SlicedOrder{
+		executor: s.executor,
+		variety:  variety,
+		params:   params,
+		quantity: params.Quantity,
+		children: make([]ChildOrder, len(quantities)),
+		early:    make(map[string]mbconnect.Order),
+		cancel:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for i, quantity := range quantities {
+		order.children[i].Quantity = quantity
+	}
+	go order.place(params, s.params.Pacing)
+	return order, nil
+}
+
+// place places the child orders with pacing, stopping at the first failure or on cancel
+func (o *SlicedOrder) place(params mbconnect.OrderParams, pacing time.Duration) {
+	defer func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.early = nil
+		close(o.done)
+	}()
+	for i := range o.children {
+		if i > 0 {
+			select {
+			case <-o.cancel:
+				return
+			case <-time.After(pacing):
+			}
+		}
+		select {
+		case <-o.cancel:
+			return
+		default:
+		}
+
+		params.Quantity = o.children[i].Quantity
+		resp, err := o.executor.PlaceOrder(o.variety, params)
+
+		o.mu.Lock()
+		if err != nil {
+			o.children[i].Error = err.Error()
+			o.err = fmt.Errorf("failed to place child order %d of %d: %w", i+1, len(o.children), err)
+			o.mu.Unlock()
+			return
+		}
+		o.children[i].OrderID = resp.OrderID
+		o.children[i].Status = mbconnect.OrderStatusOpen
+		if update, ok := o.early[resp.OrderID]; ok {
+			o.children[i].apply(update)
+			delete(o.early, resp.OrderID)
+		}
+		o.mu.Unlock()
+	}
+}
+
+// Done returns a channel that is closed when placement of the child orders ends
+func (o *SlicedOrder) Done() <-chan struct{} {
+	return o.done
+}
+
+// Wait waits for placement of the child orders to end and returns its error
+func (o *SlicedOrder) Wait() error {
+	<-o.done
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
+
+// Update applies an order update to its child order, use it with postbacks or
+// order update callbacks. Updates that may belong to a child whose order id is
+// not known yet, while placement is in progress, are applied once it is.
+func (o *SlicedOrder) Update(order mbconnect.Order) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.children {
+		if o.children[i].OrderID != "" && o.children[i].OrderID == order.OrderID {
+			o.children[i].apply(order)
+			return
+		}
+	}
+	if o.early != nil && o.mayBeChild(order) {
+		if previous, ok := o.early[order.OrderID]; !ok || !isRegression(previous.Status, previous.FilledQuantity, order) {
+			o.early[order.OrderID] = order
+		}
+	}
+}
+
+// mayBeChild reports whether the order matches the instrument, side and
+// quantity of a child that has no order id yet, the lock must be held
+func (o *SlicedOrder) mayBeChild(order mbconnect.Order) bool {
+	if order.OrderID == "" || order.Exchange != o.params.Exchange || order.Tradingsymbol != o.params.Tradingsymbol ||
+		order.TransactionType != o.params.TransactionType || (order.Variety != "" && order.Variety != o.variety) {
+		return false
+	}
+	for _, child := range o.children {
+		if child.OrderID == "" && child.Quantity == order.Quantity {
+			return true
+		}
+	}
+	return false
+}
+
+// apply sets the state of the child from an order update, updates older than
+// the child's state are ignored as they can arrive out of order
+func (c *ChildOrder) apply(order mbconnect.Order) {
+	if isRegression(c.Status, c.FilledQuantity, order) {
+		return
+	}
+	c.Status = order.Status
+	c.FilledQuantity = order.FilledQuantity
+	c.AveragePrice = order.AveragePrice
+}
+
+// isRegression reports whether the update lowers the filled quantity or moves
+// a terminal status back to open - helper function
+func isRegression(status string, filledQuantity uint, order mbconnect.Order) bool {
+	if order.FilledQuantity < filledQuantity {
+		return true
+	}
+	return !(mbconnect.Order{Status: status}).IsOpen() && order.IsOpen()
+}
+
+// Refresh updates the child orders from the order book
+func (o *SlicedOrder) Refresh() error {
+	orders, err := o.executor.Orders()
+	if err != nil {
+		return fmt.Errorf("failed to get orders: %w", err)
+	}
+	for _, order := range orders {
+		o.Update(order)
+	}
+	return nil
+}
+
+// Cancel stops placing child orders and cancels the open ones
+func (o *SlicedOrder) Cancel() error {
+	o.once.Do(func() { close(o.cancel) })
+	<-o.done
+
+	// Cancel from the latest state, so filled children aren't cancelled.
+	refreshErr := o.Refresh()
+
+	o.mu.Lock()
+	var open []string
+	for _, child := range o.children {
+		if child.OrderID != "" && (mbconnect.Order{Status: child.Status}).IsOpen() {
+			open = append(open, child.OrderID)
+		}
+	}
+	o.mu.Unlock()
+
+	var errs []error
+	if refreshErr != nil {
+		errs = append(errs, refreshErr)
+	}
+	for _, orderID := range open {
+		if _, err := o.executor.CancelOrder(o.variety, orderID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel child order %s: %w", orderID, err))
+		}
+	}
+	if err := o.Refresh(); err != nil && refreshErr == nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Status returns the aggregate fill and average price of the child orders
+func (o *SlicedOrder) Status() SliceStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	status := SliceStatus{
+		Quantity: o.quantity,
+		Children: append([]ChildOrder(nil), o.children...),
+		Complete: true,
+	}
+	var filledValue decimal.Decimal
+	for _, child := range o.children {
+		if child.OrderID != "" {
+			status.PlacedQuantity += child.Quantity
+		}
+		status.FilledQuantity += child.FilledQuantity
+		filledValue = filledValue.Add(child.AveragePrice.Mul(decimal.NewFromInt(int64(child.FilledQuantity))))
+		if child.Status != mbconnect.OrderStatusComplete {
+			status.Complete = false
+		}
+	}
+	if status.FilledQuantity > 0 {
+		status.AveragePrice = filledValue.Div(decimal.NewFromInt(int64(status.FilledQuantity))).Round(4)
+	}
+	return status
+}
@@ -0,0 +1,217 @@
+package mbbroker
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	"github.com/shopspring/decimal"
+)
+
+// fakeExecutor places orders in memory, the methods it doesn't override panic
+type fakeExecutor struct {
+	Executor
+
+	mu        sync.Mutex
+	placed    []mbconnect.OrderParams
+	cancelled []string
+	orders    []mbconnect.Order
+
+	// placing, when set, receives the order id of every placed order, which
+	// is only returned after a value is sent on release
+	placing chan string
+	release chan struct{}
+}
+
+func (f *fakeExecutor) PlaceOrder(variety string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error) {
+	f.mu.Lock()
+	f.placed = append(f.placed, params)
+	orderID := fmt.Sprintf("order-%d", len(f.placed))
+	f.mu.Unlock()
+	if f.placing != nil {
+		f.placing <- orderID
+		<-f.release
+	}
+	return mbconnect.OrderResponse{OrderID: orderID}, nil
+}
+
+func (f *fakeExecutor) CancelOrder(variety, orderID string) (mbconnect.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancelled = append(f.cancelled, orderID)
+	return mbconnect.OrderResponse{OrderID: orderID}, nil
+}
+
+func (f *fakeExecutor) Orders() ([]mbconnect.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]mbconnect.Order(nil), f.orders...), nil
+}
+
+var niftyFuture = mbconnect.Instrument{Exchange: "NFO", Tradingsymbol: "NIFTY24OCTFUT", Name: "NIFTY", LotSize: 25}
+
+var niftyBuy = mbconnect.OrderParams{
+	TransactionType: mbconnect.TransactionTypeBuy,
+	OrderType:       mbconnect.OrderTypeMarket,
+	Product:         mbconnect.ProductNRML,
+}
+
+// childUpdate returns an order update of a child of a NIFTY future buy
+func childUpdate(orderID, status string, quantity, filled uint, averagePrice string) mbconnect.Order {
+	return mbconnect.Order{
+		OrderID:         orderID,
+		Status:          status,
+		Exchange:        niftyFuture.Exchange,
+		Tradingsymbol:   niftyFuture.Tradingsymbol,
+		TransactionType: mbconnect.TransactionTypeBuy,
+		Quantity:        quantity,
+		FilledQuantity:  filled,
+		AveragePrice:    decimal.RequireFromString(averagePrice),
+	}
+}
+
+func TestSlicerSlice(t *testing.T) {
+	tests := []struct {
+		name     string
+		freeze   map[string]uint
+		quantity uint
+		want     []uint
+		wantErr  bool
+	}{
+		{"remainder", map[string]uint{"NIFTY": 100}, 250, []uint{100, 100, 50}, false},
+		{"exact", map[string]uint{"NIFTY": 100}, 200, []uint{100, 100}, false},
+		{"below freeze", map[string]uint{"NIFTY": 100}, 75, []uint{75}, false},
+		{"freeze rounded down to lot", map[string]uint{"NIFTY": 110}, 250, []uint{100, 100, 50}, false},
+		{"no freeze quantity", nil, 250, []uint{250}, false},
+		{"freeze smaller than a lot", map[string]uint{"NIFTY": 20}, 250, nil, true},
+		{"not a lot multiple", map[string]uint{"NIFTY": 100}, 30, nil, true},
+	}
+	for _, tt := range tests {
+		slicer := NewSlicer(&fakeExecutor{}, SlicerParams{FreezeQuantities: tt.freeze})
+		got, err := slicer.Slice(niftyFuture, tt.quantity)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Slice(%d) error = %v, wantErr %v", tt.name, tt.quantity, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Slice(%d) = %v, want %v", tt.name, tt.quantity, got, tt.want)
+		}
+	}
+}
+
+func TestSlicedOrderEarlyUpdate(t *testing.T) {
+	executor := &fakeExecutor{placing: make(chan string), release: make(chan struct{})}
+	slicer := NewSlicer(executor, SlicerParams{FreezeQuantities: map[string]uint{"NIFTY": 100}, Pacing: time.Millisecond})
+	params := niftyBuy
+	params.Quantity = 200
+	order, err := slicer.PlaceOrder(niftyFuture, mbconnect.VarietyRegular, params)
+	if err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+
+	// The first child fills, and a stale open update follows, before
+	// PlaceOrder returns its order id.
+	first := <-executor.placing
+	order.Update(childUpdate(first, mbconnect.OrderStatusComplete, 100, 100, "24500"))
+	order.Update(childUpdate(first, mbconnect.OrderStatusOpen, 100, 0, "0"))
+	// An update of another instrument is not buffered.
+	other := childUpdate("order-x", mbconnect.OrderStatusComplete, 100, 100, "1")
+	other.Tradingsymbol = "BANKNIFTY24OCTFUT"
+	order.Update(other)
+	executor.release <- struct{}{}
+
+	<-executor.placing
+	executor.release <- struct{}{}
+	if err := order.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	status := order.Status()
+	if got := status.Children[0]; got.Status != mbconnect.OrderStatusComplete || got.FilledQuantity != 100 {
+		t.Errorf("first child = %+v, want complete with 100 filled", got)
+	}
+	if got := status.Children[1]; got.Status != mbconnect.OrderStatusOpen || got.FilledQuantity != 0 {
+		t.Errorf("second child = %+v, want open with nothing filled", got)
+	}
+	if status.PlacedQuantity != 200 || status.FilledQuantity != 100 || status.Complete {
+		t.Errorf("Status() = %+v, want 200 placed, 100 filled, not complete", status)
+	}
+}
+
+func TestSlicedOrderIgnoresRegression(t *testing.T) {
+	executor := &fakeExecutor{}
+	slicer := NewSlicer(executor, SlicerParams{FreezeQuantities: map[string]uint{"NIFTY": 100}, Pacing: time.Millisecond})
+	params := niftyBuy
+	params.Quantity = 100
+	order, err := slicer.PlaceOrder(niftyFuture, mbconnect.VarietyRegular, params)
+	if err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	if err := order.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	updates := []struct {
+		update     mbconnect.Order
+		wantStatus string
+		wantFilled uint
+	}{
+		{childUpdate("order-1", mbconnect.OrderStatusOpen, 100, 50, "24500"), mbconnect.OrderStatusOpen, 50},
+		{childUpdate("order-1", mbconnect.OrderStatusOpen, 100, 25, "24500"), mbconnect.OrderStatusOpen, 50},
+		{childUpdate("order-1", mbconnect.OrderStatusComplete, 100, 100, "24501"), mbconnect.OrderStatusComplete, 100},
+		{childUpdate("order-1", mbconnect.OrderStatusOpen, 100, 100, "24501"), mbconnect.OrderStatusComplete, 100},
+	}
+	for i, u := range updates {
+		order.Update(u.update)
+		child := order.Status().Children[0]
+		if child.Status != u.wantStatus || child.FilledQuantity != u.wantFilled {
+			t.Errorf("update %d: child = %s with %d filled, want %s with %d filled", i, child.Status, child.FilledQuantity, u.wantStatus, u.wantFilled)
+		}
+	}
+}
+
+func TestSlicedOrderStatus(t *testing.T) {
+	executor := &fakeExecutor{}
+	slicer := NewSlicer(executor, SlicerParams{FreezeQuantities: map[string]uint{"NIFTY": 100}, Pacing: time.Millisecond})
+	params := niftyBuy
+	params.Quantity = 250
+	order, err := slicer.PlaceOrder(niftyFuture, mbconnect.VarietyRegular, params)
+	if err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	if err := order.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if len(executor.placed) != 3 {
+		t.Fatalf("placed %d child orders, want 3", len(executor.placed))
+	}
+
+	executor.orders = []mbconnect.Order{
+		childUpdate("order-1", mbconnect.OrderStatusComplete, 100, 100, "100"),
+		childUpdate("order-2", mbconnect.OrderStatusComplete, 100, 100, "101"),
+		childUpdate("order-3", mbconnect.OrderStatusOpen, 50, 20, "102.5"),
+	}
+	if err := order.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	status := order.Status()
+	// (100*100 + 100*101 + 20*102.5) / 220
+	if want := decimal.RequireFromString("100.6818"); !status.AveragePrice.Equal(want) {
+		t.Errorf("AveragePrice = %s, want %s", status.AveragePrice, want)
+	}
+	if status.Quantity != 250 || status.PlacedQuantity != 250 || status.FilledQuantity != 220 || status.Complete {
+		t.Errorf("Status() = %+v, want 250 placed, 220 filled, not complete", status)
+	}
+
+	order.Update(childUpdate("order-3", mbconnect.OrderStatusComplete, 50, 50, "102.5"))
+	status = order.Status()
+	// (100*100 + 100*101 + 50*102.5) / 250
+	if want := decimal.RequireFromString("100.9"); !status.AveragePrice.Equal(want) {
+		t.Errorf("AveragePrice = %s, want %s", status.AveragePrice, want)
+	}
+	if !status.Complete {
+		t.Errorf("Status() = %+v, want complete", status)
+	}
+}
@@ -0,0 +1,351 @@
+package mbcandles
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+)
+
+const (
+	defaultMaxHistory int           = 500
+	defaultGrace      time.Duration = 2 * time.Second
+)
+
+// IST is the exchange timezone (Asia/Kolkata)
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+// Session is the trading session as offsets from midnight IST
+type Session struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// Trading sessions
+var (
+	SessionNSE = Session{Open: 9*time.Hour + 15*time.Minute, Close: 15*time.Hour + 30*time.Minute}
+	SessionMCX = Session{Open: 9 * time.Hour, Close: 23*time.Hour + 55*time.Minute}
+)
+
+// CandleEvent is sent when a candle is updated or completed
+type CandleEvent struct {
+	InstrumentToken uint32
+	Interval        time.Duration
+	Candle          mbconnect.Candle
+	Complete        bool
+}
+
+// Aggregator builds time-aligned OHLCV candles from ticks. Candles are aligned
+// to the session open in IST, so 5 minute candles start at 09:15, 09:20 and so
+// on. A candle completes once a tick arrives `grace` after its end, or on
+// `Flush`; late ticks within the grace period still update it.
+type Aggregator struct {
+	intervals  []time.Duration
+	session    Session
+	grace      time.Duration
+	maxHistory int
+
+	onCandle   func(CandleEvent)
+	onUpdate   func(CandleEvent)
+	onLateTick func(mbticker.Tick)
+
+	mu     sync.Mutex
+	series map[seriesKey]*series
+	volume map[uint32]sessionVolume
+}
+
+// sessionVolume is the latest cumulative volume of a token in a session, the
+// exchange resets the cumulative volume every session
+type sessionVolume struct {
+	day    int64
+	volume uint32
+}
+
+// seriesKey identifies the candles of a token and interval
+type seriesKey struct {
+	token    uint32
+	interval time.Duration
+}
+
+// series is the state of the candles of a token and interval
+type series struct {
+	open       map[int64]*mbconnect.Candle
+	volumeBase map[int64]uint32
+	lastTick   map[int64]time.Time
+	emitted    time.Time
+	history    []mbconnect.Candle
+}
+
+// NewAggregator creates a new aggregator for the intervals, e.g. time.Minute and 5*time.Minute
+func NewAggregator(intervals ...time.Duration) (*Aggregator, error) {
+	if len(intervals) == 0 {
+		return nil, fmt.Errorf("`intervals` are required")
+	}
+	for _, interval := range intervals {
+		if interval < time.Second || interval > 24*time.Hour {
+			return nil, fmt.Errorf("invalid `interval` %v", interval)
+		}
+	}
+	return &Aggregator{
+		intervals:  intervals,
+		session:    SessionNSE,
+		grace:      defaultGrace,
+		maxHistory: defaultMaxHistory,
+		series:     make(map[seriesKey]*series),
+		volume:     make(map[uint32]sessionVolume),
+	}, nil
+}
+
+// SetSession sets the trading session, ticks outside the session are ignored.
+func (a *Aggregator) SetSession(session Session) {
+	a.session = session
+}
+
+// SetGrace sets how long after its end a candle accepts late ticks.
+func (a *Aggregator) SetGrace(grace time.Duration) {
+	a.grace = grace
+}
+
+// SetMaxHistory sets the number of completed candles kept per token and interval.
+func (a *Aggregator) SetMaxHistory(maxHistory int) {
+	a.maxHistory = maxHistory
+}
+
+// OnCandle sets the callback for completed candles.
+func (a *Aggregator) OnCandle(fn func(CandleEvent)) {
+	a.onCandle = fn
+}
+
+// OnUpdate sets the callback for in-progress candle updates.
+func (a *Aggregator) OnUpdate(fn func(CandleEvent)) {
+	a.onUpdate = fn
+}
+
+// OnLateTick sets the callback for ticks that arrive after their candle completed.
+func (a *Aggregator) OnLateTick(fn func(mbticker.Tick)) {
+	a.onLateTick = fn
+}
+
+// Seed warms up the history of the token and interval with historical candles.
+// Ticks older than the last seeded candle are treated as late.
+func (a *Aggregator) Seed(token uint32, interval time.Duration, candles []mbconnect.Candle) {
+	sorted := append([]mbconnect.Candle(nil), candles...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date.Time)
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.getSeries(token, interval)
+	s.history = append(s.history, sorted...)
+	a.trimHistory(s)
+	if len(sorted) > 0 {
+		end := sorted[len(sorted)-1].Date.Add(interval)
+		if end.After(s.emitted) {
+			s.emitted = end
+		}
+	}
+}
+
+// History returns the completed candles of the token and interval, oldest first
+func (a *Aggregator) History(token uint32, interval time.Duration) []mbconnect.Candle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.series[seriesKey{token: token, interval: interval}]
+	if !ok {
+		return nil
+	}
+	return append([]mbconnect.Candle(nil), s.history...)
+}
+
+// Current returns the latest in-progress candle of the token and interval
+func (a *Aggregator) Current(token uint32, interval time.Duration) (mbconnect.Candle, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.series[seriesKey{token: token, interval: interval}]
+	if !ok || len(s.open) == 0 {
+		return mbconnect.Candle{}, false
+	}
+	var latest int64
+	for start := range s.open {
+		if start > latest {
+			latest = start
+		}
+	}
+	return *s.open[latest], true
+}
+
+// AddTick updates the candles of the tick's instrument. The tick time is its
+// exchange timestamp, falling back to the last trade time and then the local time.
+func (a *Aggregator) AddTick(tick mbticker.Tick) {
+	ts := tick.Timestamp
+	if ts.IsZero() {
+		ts = tick.LastTradeTime
+	}
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	ts = ts.In(IST)
+
+	var events []CandleEvent
+	late := false
+
+	a.mu.Lock()
+	cumVolume, hasVolume := tick.VolumeTraded, tick.VolumeTraded > 0
+	day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, IST).Unix()
+	sv := a.volume[tick.InstrumentToken]
+	if sv.day != day {
+		sv = sessionVolume{day: day}
+	}
+	previousVolume := sv.volume
+	if hasVolume && cumVolume > sv.volume {
+		sv.volume = cumVolume
+	}
+	a.volume[tick.InstrumentToken] = sv
+
+	for _, interval := range a.intervals {
+		start, ok := a.bucketStart(ts, interval)
+		if !ok {
+			continue
+		}
+		s := a.getSeries(tick.InstrumentToken, interval)
+		if start.Before(s.emitted) {
+			late = true
+			continue
+		}
+
+		key := start.Unix()
+		candle, exists := s.open[key]
+		if !exists {
+			candle = &mbconnect.Candle{
+				Date: mbconnect.Time{Time: start},
+				Open: tick.LastPrice,
+				High: tick.LastPrice,
+				Low:  tick.LastPrice,
+			}
+			s.open[key] = candle
+			s.volumeBase[key] = previousVolume
+			if previousVolume == 0 {
+				s.volumeBase[key] = cumVolume
+			}
+		}
+		candle.High = max(candle.High, tick.LastPrice)
+		candle.Low = min(candle.Low, tick.LastPrice)
+		// An out-of-order tick updates the range but not the close.
+		if !ts.Before(s.lastTick[key]) {
+			candle.Close = tick.LastPrice
+			s.lastTick[key] = ts
+		}
+		if hasVolume && cumVolume > s.volumeBase[key] {
+			candle.Volume = max(candle.Volume, uint64(cumVolume-s.volumeBase[key]))
+		}
+		if tick.OI > 0 {
+			candle.OI = uint64(tick.OI)
+		}
+		events = append(events, CandleEvent{
+			InstrumentToken: tick.InstrumentToken,
+			Interval:        interval,
+			Candle:          *candle,
+		})
+
+		events = append(events, a.completeSeries(tick.InstrumentToken, interval, s, ts)...)
+	}
+	a.mu.Unlock()
+
+	if late && a.onLateTick != nil {
+		a.onLateTick(tick)
+	}
+	a.dispatch(events)
+}
+
+// Flush completes every candle that ended more than the grace period before `now`.
+// Call it periodically, or at session close, when ticks stop arriving.
+func (a *Aggregator) Flush(now time.Time) {
+	var events []CandleEvent
+	a.mu.Lock()
+	for key, s := range a.series {
+		events = append(events, a.completeSeries(key.token, key.interval, s, now)...)
+	}
+	a.mu.Unlock()
+	a.dispatch(events)
+}
+
+// completeSeries completes the open candles that ended more than the grace period before `now`
+func (a *Aggregator) completeSeries(token uint32, interval time.Duration, s *series, now time.Time) []CandleEvent {
+	var starts []int64
+	for start := range s.open {
+		if !time.Unix(start, 0).Add(interval + a.grace).After(now) {
+			starts = append(starts, start)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	events := make([]CandleEvent, 0, len(starts))
+	for _, start := range starts {
+		candle := *s.open[start]
+		delete(s.open, start)
+		delete(s.volumeBase, start)
+		delete(s.lastTick, start)
+		s.history = append(s.history, candle)
+		if end := candle.Date.Add(interval); end.After(s.emitted) {
+			s.emitted = end
+		}
+		events = append(events, CandleEvent{
+			InstrumentToken: token,
+			Interval:        interval,
+			Candle:          candle,
+			Complete:        true,
+		})
+	}
+	a.trimHistory(s)
+	return events
+}
+
+// bucketStart returns the start of the candle containing `ts`, false if outside the session
+func (a *Aggregator) bucketStart(ts time.Time, interval time.Duration) (time.Time, bool) {
+	day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, IST)
+	open := day.Add(a.session.Open)
+	closeTime := day.Add(a.session.Close)
+	if ts.Before(open) || !ts.Before(closeTime) {
+		return time.Time{}, false
+	}
+	return open.Add(ts.Sub(open).Truncate(interval)), true
+}
+
+// getSeries returns the series of the token and interval, creating it if needed
+func (a *Aggregator) getSeries(token uint32, interval time.Duration) *series {
+	key := seriesKey{token: token, interval: interval}
+	s, ok := a.series[key]
+	if !ok {
+		s = &series{
+			open:       make(map[int64]*mbconnect.Candle),
+			volumeBase: make(map[int64]uint32),
+			lastTick:   make(map[int64]time.Time),
+		}
+		a.series[key] = s
+	}
+	return s
+}
+
+// trimHistory drops the oldest completed candles beyond the maximum history
+func (a *Aggregator) trimHistory(s *series) {
+	if a.maxHistory > 0 && len(s.history) > a.maxHistory {
+		s.history = append([]mbconnect.Candle(nil), s.history[len(s.history)-a.maxHistory:]...)
+	}
+}
+
+// dispatch invokes the callbacks outside the lock
+func (a *Aggregator) dispatch(events []CandleEvent) {
+	for _, event := range events {
+		if event.Complete {
+			if a.onCandle != nil {
+				a.onCandle(event)
+			}
+		} else if a.onUpdate != nil {
+			a.onUpdate(event)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package mbcandles
+
+import (
+	"testing"
+	"time"
+
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+)
+
+func TestAggregatorVolumeResetsEachSession(t *testing.T) {
+	a, err := NewAggregator(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day1 := time.Date(2024, 10, 30, 10, 0, 0, 0, IST)
+	day2 := day1.AddDate(0, 0, 1)
+
+	ticks := []mbticker.Tick{
+		// Day 1 closes with a cumulative volume of 50000.
+		{InstrumentToken: 1, LastPrice: 100, VolumeTraded: 10000, Timestamp: day1},
+		{InstrumentToken: 1, LastPrice: 101, VolumeTraded: 50000, Timestamp: day1.Add(30 * time.Second)},
+		// Day 2 starts again from a lower cumulative volume.
+		{InstrumentToken: 1, LastPrice: 102, VolumeTraded: 1000, Timestamp: day2},
+		{InstrumentToken: 1, LastPrice: 103, VolumeTraded: 1600, Timestamp: day2.Add(30 * time.Second)},
+		{InstrumentToken: 1, LastPrice: 104, VolumeTraded: 2000, Timestamp: day2.Add(70 * time.Second)},
+	}
+	for _, tick := range ticks {
+		a.AddTick(tick)
+	}
+	a.Flush(day2.Add(time.Hour))
+
+	history := a.History(1, time.Minute)
+	if len(history) != 3 {
+		t.Fatalf("got %d candles, want 3", len(history))
+	}
+	want := []uint64{40000, 600, 400}
+	for i, candle := range history {
+		if candle.Volume != want[i] {
+			t.Errorf("candle %v volume = %d, want %d", candle.Date.Time, candle.Volume, want[i])
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package mbconnect
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"time"
@@ -57,6 +59,42 @@ const (
 	URIIndicesAll              string = "/indices/all"
 	URIIndicesByExchange       string = "/indices/%s/info"
 	URIIndicesIndexInstruments string = "/indices/%s/%s/instruments"
+
+	// market quotes
+	URIQuote     string = "/quote"
+	URIQuoteLTP  string = "/quote/ltp"
+	URIQuoteOHLC string = "/quote/ohlc"
+
+	// historical data
+	URIHistoricalData string = "/instruments/historical/%d/%s"
+
+	// orders
+	URIPlaceOrder      string = "/orders/%s"
+	URIModifyOrder     string = "/orders/%s/%s"
+	URICancelOrder     string = "/orders/%s/%s"
+	URIGetOrders       string = "/orders"
+	URIGetOrderHistory string = "/orders/%s"
+	URIGetTrades       string = "/trades"
+	URIGetOrderTrades  string = "/orders/%s/trades"
+
+	// portfolio
+	URIGetPositions string = "/portfolio/positions"
+	URIGetHoldings  string = "/portfolio/holdings"
+
+	// user
+	URIUserMargins        string = "/user/margins"
+	URIUserMarginsSegment string = "/user/margins/%s"
+
+	// margins and charges
+	URIOrderMargins  string = "/margins/orders"
+	URIBasketMargins string = "/margins/basket"
+
+	// gtt
+	URIGetGTTs   string = "/gtt/triggers"
+	URIGetGTT    string = "/gtt/triggers/%d"
+	URIPlaceGTT  string = "/gtt/triggers"
+	URIModifyGTT string = "/gtt/triggers/%d"
+	URIDeleteGTT string = "/gtt/triggers/%d"
 )
 
 // New creates a new client.
@@ -102,6 +140,16 @@ func (c *Client) SetEnctoken(enctoken string) {
 	c.enctoken = enctoken
 }
 
+// UserID returns the user id of the instance.
+func (c *Client) UserID() string {
+	return c.userId
+}
+
+// Enctoken returns the enctoken of the instance.
+func (c *Client) Enctoken() string {
+	return c.enctoken
+}
+
 func (c *Client) doEnvelope(method, uri string, params url.Values, headers http.Header, v interface{}) error {
 	if params == nil {
 		params = url.Values{}
@@ -123,6 +171,31 @@ func (c *Client) doRaw(method, uri string, reqBody []byte, headers http.Header)
 	return c.httpClient.DoRaw(method, c.baseURI+uri, reqBody, headers)
 }
 
+func (c *Client) doRawEnvelope(method, uri string, reqBody []byte, headers http.Header, v interface{}) error {
+	resp, err := c.doRaw(method, uri, reqBody, headers)
+	if err != nil {
+		return err
+	}
+	return readEnvelope(resp, v)
+}
+
+func (c *Client) doStream(method, uri string, params url.Values, headers http.Header) (*http.Response, error) {
+	headers = c.getHeaders(headers)
+	if streamer, ok := c.httpClient.(HTTPStreamer); ok {
+		return streamer.DoStream(method, c.baseURI+uri, params, headers)
+	}
+
+	resp, err := c.httpClient.Do(method, c.baseURI+uri, params, headers)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Response.StatusCode >= http.StatusBadRequest {
+		return nil, readEnvelope(resp, nil)
+	}
+	resp.Response.Body = io.NopCloser(bytes.NewReader(resp.Body))
+	return resp.Response, nil
+}
+
 func (c *Client) getHeaders(headers http.Header) http.Header {
 	if headers == nil {
 		headers = map[string][]string{}
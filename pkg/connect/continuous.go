@@ -0,0 +1,183 @@
+package mbconnect
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ContractSeries is the position of a futures contract in the expiry cycle
+type ContractSeries int
+
+// Contract series
+const (
+	SeriesNear ContractSeries = iota
+	SeriesNext
+	SeriesFar
+)
+
+// String returns the name of the contract series
+func (s ContractSeries) String() string {
+	switch s {
+	case SeriesNear:
+		return "near"
+	case SeriesNext:
+		return "next"
+	case SeriesFar:
+		return "far"
+	default:
+		return fmt.Sprintf("series(%d)", int(s))
+	}
+}
+
+// ContinuousFuture is a logical futures contract, e.g. NIFTY near month future
+type ContinuousFuture struct {
+	Exchange string
+	Name     string
+	Series   ContractSeries
+}
+
+// String returns the contract as `EXCHANGE:NAME-series-FUT`
+func (f ContinuousFuture) String() string {
+	return fmt.Sprintf("%s:%s-%s-%s", f.Exchange, f.Name, f.Series, InstrumentTypeFUT)
+}
+
+// RolloverRule decides when a contract rolls to the next expiry.
+// With `DaysBefore` 0 the contract rolls on the day after expiry,
+// otherwise it rolls `DaysBefore` calendar days before expiry.
+type RolloverRule struct {
+	DaysBefore int
+}
+
+// RolloverEvent is sent when a continuous future resolves to a new contract
+type RolloverEvent struct {
+	Contract ContinuousFuture
+	From     Instrument
+	To       Instrument
+	Date     time.Time
+}
+
+// FuturesResolver resolves continuous futures to concrete instruments
+type FuturesResolver struct {
+	client     *Client
+	rule       RolloverRule
+	onRollover func(RolloverEvent)
+
+	mu      sync.Mutex
+	current map[ContinuousFuture]Instrument
+}
+
+// NewFuturesResolver creates a new futures resolver
+func NewFuturesResolver(client *Client, rule RolloverRule) *FuturesResolver {
+	return &FuturesResolver{
+		client:  client,
+		rule:    rule,
+		current: make(map[ContinuousFuture]Instrument),
+	}
+}
+
+// OnRollover sets the callback invoked when a contract rolls over
+func (r *FuturesResolver) OnRollover(fn func(RolloverEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onRollover = fn
+}
+
+// Resolve returns the instrument of the continuous future for the date
+func (r *FuturesResolver) Resolve(contract ContinuousFuture, date time.Time) (Instrument, error) {
+	if contract.Exchange == "" {
+		return Instrument{}, fmt.Errorf("`exchange` is required")
+	}
+	if contract.Name == "" {
+		return Instrument{}, fmt.Errorf("`name` is required")
+	}
+	if contract.Series < SeriesNear {
+		return Instrument{}, fmt.Errorf("invalid `series` %d", contract.Series)
+	}
+
+	expiry, err := r.resolveExpiry(contract, date)
+	if err != nil {
+		return Instrument{}, err
+	}
+
+	instruments, err := r.client.InstrumentsQuery(InstrumentsQueryParams{
+		Exchange:       contract.Exchange,
+		Name:           contract.Name,
+		Expiry:         expiry,
+		InstrumentType: InstrumentTypeFUT,
+	})
+	if err != nil {
+		return Instrument{}, err
+	}
+	if len(instruments) == 0 {
+		return Instrument{}, fmt.Errorf("no instrument for %s expiring on %s", contract, expiry)
+	}
+	instrument := instruments[0]
+
+	r.mu.Lock()
+	previous, ok := r.current[contract]
+	r.current[contract] = instrument
+	onRollover := r.onRollover
+	r.mu.Unlock()
+
+	if ok && previous.InstrumentToken != instrument.InstrumentToken && onRollover != nil {
+		onRollover(RolloverEvent{
+			Contract: contract,
+			From:     previous,
+			To:       instrument,
+			Date:     date,
+		})
+	}
+
+	return instrument, nil
+}
+
+// resolveExpiry returns the expiry of the contract for the date using the
+// futures expiries of the segment
+func (r *FuturesResolver) resolveExpiry(contract ContinuousFuture, date time.Time) (string, error) {
+	segmentExpiries, err := r.client.FNOSegmentExpiries(contract.Name)
+	if err != nil {
+		return "", err
+	}
+	segment := contract.Exchange + "-" + InstrumentTypeFUT
+	expiries := segmentExpiries[segment]
+	if len(expiries) == 0 {
+		return "", fmt.Errorf("no %s expiries for %s", segment, contract.Name)
+	}
+
+	active, err := r.activeExpiries(expiries, date)
+	if err != nil {
+		return "", err
+	}
+	if int(contract.Series) >= len(active) {
+		return "", fmt.Errorf("no %s contract for %s on %s", contract.Series, contract.Name, date.Format(expiryLayout))
+	}
+	return active[contract.Series], nil
+}
+
+// activeExpiries returns the sorted expiries that have not rolled over on the date
+func (r *FuturesResolver) activeExpiries(expiries []string, date time.Time) ([]string, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	var active []string
+	for _, expiry := range expiries {
+		expiryDate, err := time.Parse(expiryLayout, expiry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry %q: %w", expiry, err)
+		}
+		if r.isActive(expiryDate, day) {
+			active = append(active, expiry)
+		}
+	}
+	sort.Strings(active)
+	return active, nil
+}
+
+// isActive reports whether a contract expiring on `expiry` is still held on `day`
+func (r *FuturesResolver) isActive(expiry, day time.Time) bool {
+	if r.rule.DaysBefore <= 0 {
+		return !day.After(expiry)
+	}
+	return day.Before(expiry.AddDate(0, 0, -r.rule.DaysBefore))
+}
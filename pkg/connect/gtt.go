@@ -0,0 +1,248 @@
+package mbconnect
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GTT trigger types
+const (
+	GTTTypeSingle = "single"
+	GTTTypeOCO    = "two-leg"
+)
+
+// GTT statuses
+const (
+	GTTStatusActive    = "active"
+	GTTStatusTriggered = "triggered"
+	GTTStatusDisabled  = "disabled"
+	GTTStatusExpired   = "expired"
+	GTTStatusCancelled = "cancelled"
+	GTTStatusRejected  = "rejected"
+	GTTStatusDeleted   = "deleted"
+)
+
+// GTTCondition is a struct that represents the trigger condition of a GTT
+type GTTCondition struct {
+	Exchange        string    `json:"exchange"`
+	Tradingsymbol   string    `json:"tradingsymbol"`
+	InstrumentToken uint32    `json:"instrument_token,omitempty"`
+	TriggerValues   []float64 `json:"trigger_values"`
+	LastPrice       float64   `json:"last_price"`
+}
+
+// GTTOrder is a struct that represents the order placed when a GTT triggers
+type GTTOrder struct {
+	Exchange        string  `json:"exchange"`
+	Tradingsymbol   string  `json:"tradingsymbol"`
+	TransactionType string  `json:"transaction_type"`
+	Quantity        uint    `json:"quantity"`
+	OrderType       string  `json:"order_type"`
+	Product         string  `json:"product"`
+	Price           float64 `json:"price"`
+}
+
+// GTTOrderResult is a struct that represents the outcome of a triggered GTT order
+type GTTOrderResult struct {
+	OrderResult struct {
+		OrderID         string `json:"order_id"`
+		RejectionReason string `json:"rejection_reason"`
+		Status          string `json:"status"`
+	} `json:"order_result"`
+	Timestamp Time `json:"timestamp"`
+}
+
+// GTT is a struct that represents a GTT trigger
+type GTT struct {
+	ID            uint         `json:"id"`
+	UserID        string       `json:"user_id"`
+	ParentTrigger *uint        `json:"parent_trigger"`
+	Type          string       `json:"type"`
+	CreatedAt     Time         `json:"created_at"`
+	UpdatedAt     Time         `json:"updated_at"`
+	ExpiresAt     Time         `json:"expires_at"`
+	Status        string       `json:"status"`
+	Condition     GTTCondition `json:"condition"`
+	Orders        []GTTLeg     `json:"orders"`
+}
+
+// GTTLeg is a struct that represents a GTT order and its result once triggered
+type GTTLeg struct {
+	GTTOrder
+	Result *GTTOrderResult `json:"result"`
+}
+
+// GTTParams is a struct that represents the parameters of a GTT. For a single
+// trigger, `TriggerValues` and `Orders` have one entry. For an OCO trigger they
+// have two, the stop-loss below the last price first and the target above it.
+type GTTParams struct {
+	Instrument    Instrument
+	Type          string
+	LastPrice     float64
+	TriggerValues []float64
+	Orders        []GTTOrderParams
+}
+
+// GTTOrderParams is a struct that represents the order of a GTT leg
+type GTTOrderParams struct {
+	TransactionType string
+	Quantity        uint
+	OrderType       string
+	Product         string
+	Price           float64
+}
+
+// GTTResponse is a struct that represents the response of a GTT request
+type GTTResponse struct {
+	TriggerID uint `json:"trigger_id"`
+}
+
+// GET /gtt/triggers - Get the GTT triggers
+func (c *Client) GTTs() ([]GTT, error) {
+	var gtts []GTT
+	err := c.doEnvelope(http.MethodGet, URIGetGTTs, nil, nil, &gtts)
+	return gtts, err
+}
+
+// GET /gtt/triggers/:trigger_id - Get a GTT trigger
+func (c *Client) GTT(triggerID uint) (GTT, error) {
+	var gtt GTT
+	if triggerID == 0 {
+		return gtt, NewError(InputError, "`trigger_id` is required", nil)
+	}
+	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetGTT, triggerID), nil, nil, &gtt)
+	return gtt, err
+}
+
+// POST /gtt/triggers - Create a GTT trigger
+func (c *Client) PlaceGTT(params GTTParams) (GTTResponse, error) {
+	var resp GTTResponse
+	values, err := params.values()
+	if err != nil {
+		return resp, err
+	}
+	err = c.doEnvelope(http.MethodPost, URIPlaceGTT, values, nil, &resp)
+	return resp, err
+}
+
+// PUT /gtt/triggers/:trigger_id - Modify a GTT trigger
+func (c *Client) ModifyGTT(triggerID uint, params GTTParams) (GTTResponse, error) {
+	var resp GTTResponse
+	if triggerID == 0 {
+		return resp, NewError(InputError, "`trigger_id` is required", nil)
+	}
+	values, err := params.values()
+	if err != nil {
+		return resp, err
+	}
+	err = c.doEnvelope(http.MethodPut, fmt.Sprintf(URIModifyGTT, triggerID), values, nil, &resp)
+	return resp, err
+}
+
+// DELETE /gtt/triggers/:trigger_id - Delete a GTT trigger
+func (c *Client) DeleteGTT(triggerID uint) (GTTResponse, error) {
+	var resp GTTResponse
+	if triggerID == 0 {
+		return resp, NewError(InputError, "`trigger_id` is required", nil)
+	}
+	err := c.doEnvelope(http.MethodDelete, fmt.Sprintf(URIDeleteGTT, triggerID), nil, nil, &resp)
+	return resp, err
+}
+
+// Validate returns an `InputError` if the trigger values or order prices are
+// not tick aligned, or the quantities are not lot aligned, for the instrument
+func (p GTTParams) Validate() error {
+	if p.Instrument.Exchange == "" || p.Instrument.Tradingsymbol == "" {
+		return NewError(InputError, "`instrument` is required", nil)
+	}
+	if p.LastPrice <= 0 {
+		return NewError(InputError, "`last_price` is required", nil)
+	}
+
+	legs := 0
+	switch p.Type {
+	case GTTTypeSingle:
+		legs = 1
+	case GTTTypeOCO:
+		legs = 2
+	default:
+		return NewError(InputError, fmt.Sprintf("invalid `type` %q", p.Type), nil)
+	}
+	if len(p.TriggerValues) != legs || len(p.Orders) != legs {
+		return NewError(InputError, fmt.Sprintf("%s triggers need %d trigger values and orders", p.Type, legs), nil)
+	}
+
+	for _, trigger := range p.TriggerValues {
+		if err := p.Instrument.ValidatePrice(trigger); err != nil {
+			return NewError(InputError, fmt.Sprintf("trigger value: %v", err), nil)
+		}
+		if trigger == p.LastPrice {
+			return NewError(InputError, "trigger values can't equal the last price", nil)
+		}
+	}
+	if p.Type == GTTTypeOCO && !(p.TriggerValues[0] < p.LastPrice && p.LastPrice < p.TriggerValues[1]) {
+		return NewError(InputError, "OCO trigger values must be below and above the last price", nil)
+	}
+
+	for _, order := range p.Orders {
+		if order.TransactionType != TransactionTypeBuy && order.TransactionType != TransactionTypeSell {
+			return NewError(InputError, fmt.Sprintf("invalid `transaction_type` %q", order.TransactionType), nil)
+		}
+		if order.Product == "" {
+			return NewError(InputError, "`product` is required", nil)
+		}
+		if order.OrderType != OrderTypeLimit {
+			return NewError(InputError, "GTT orders must be `LIMIT` orders", nil)
+		}
+		if err := p.Instrument.ValidateQuantity(order.Quantity); err != nil {
+			return err
+		}
+		if err := p.Instrument.ValidatePrice(order.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// values validates and encodes the GTT parameters
+func (p GTTParams) values() (url.Values, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	condition, err := json.Marshal(GTTCondition{
+		Exchange:        p.Instrument.Exchange,
+		Tradingsymbol:   p.Instrument.Tradingsymbol,
+		InstrumentToken: p.Instrument.InstrumentToken,
+		TriggerValues:   p.TriggerValues,
+		LastPrice:       p.LastPrice,
+	})
+	if err != nil {
+		return nil, NewError(InputError, "failed to encode `condition`", nil)
+	}
+
+	orders := make([]GTTOrder, len(p.Orders))
+	for i, order := range p.Orders {
+		orders[i] = GTTOrder{
+			Exchange:        p.Instrument.Exchange,
+			Tradingsymbol:   p.Instrument.Tradingsymbol,
+			TransactionType: order.TransactionType,
+			Quantity:        order.Quantity,
+			OrderType:       order.OrderType,
+			Product:         order.Product,
+			Price:           order.Price,
+		}
+	}
+	ordersJSON, err := json.Marshal(orders)
+	if err != nil {
+		return nil, NewError(InputError, "failed to encode `orders`", nil)
+	}
+
+	params := url.Values{}
+	params.Set("type", p.Type)
+	params.Set("condition", string(condition))
+	params.Set("orders", string(ordersJSON))
+	return params, nil
+}
@@ -0,0 +1,210 @@
+package mbconnect
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Candle intervals
+const (
+	IntervalMinute   = "minute"
+	Interval3Minute  = "3minute"
+	Interval5Minute  = "5minute"
+	Interval10Minute = "10minute"
+	Interval15Minute = "15minute"
+	Interval30Minute = "30minute"
+	Interval60Minute = "60minute"
+	IntervalDay      = "day"
+)
+
+const (
+	// historicalTimeLayout is the layout of the `from` and `to` params
+	historicalTimeLayout = "2006-01-02 15:04:05"
+	// historicalConcurrency is the number of chunks fetched concurrently
+	historicalConcurrency = 3
+	// historicalRateInterval is the minimum interval between chunk requests
+	historicalRateInterval = 350 * time.Millisecond
+)
+
+// historicalMaxDays is the maximum range in days per request by interval
+var historicalMaxDays = map[string]int{
+	IntervalMinute:   60,
+	Interval3Minute:  100,
+	Interval5Minute:  100,
+	Interval10Minute: 100,
+	Interval15Minute: 200,
+	Interval30Minute: 200,
+	Interval60Minute: 400,
+	IntervalDay:      2000,
+}
+
+// Candle is a struct that represents an OHLCV candle, `OI` is set when requested
+type Candle struct {
+	Date   Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume uint64
+	OI     uint64
+}
+
+// UnmarshalJSON decodes a candle from `[timestamp, open, high, low, close, volume, oi]`
+func (c *Candle) UnmarshalJSON(b []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	if len(fields) < 6 {
+		return fmt.Errorf("invalid candle %s", b)
+	}
+	if err := json.Unmarshal(fields[0], &c.Date); err != nil {
+		return err
+	}
+	values := []interface{}{&c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.OI}
+	for i, v := range values {
+		if i+1 >= len(fields) {
+			break
+		}
+		if err := json.Unmarshal(fields[i+1], v); err != nil {
+			return fmt.Errorf("invalid candle %s: %w", b, err)
+		}
+	}
+	return nil
+}
+
+// historicalData is the envelope payload of historical data
+type historicalData struct {
+	Candles []Candle `json:"candles"`
+}
+
+// GET /instruments/historical/:token/:interval - Get historical candles
+//
+// Ranges longer than the maximum for the interval are split into chunks that
+// are fetched concurrently within the rate limit. Candles on chunk boundaries
+// are de-duplicated and the result is sorted by date.
+func (c *Client) HistoricalData(token uint32, interval string, from, to time.Time, continuous, oi bool) ([]Candle, error) {
+	if token == 0 {
+		return nil, fmt.Errorf("`token` is required")
+	}
+	maxDays, ok := historicalMaxDays[interval]
+	if !ok {
+		return nil, fmt.Errorf("invalid `interval` %q", interval)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("`from` must be before `to`")
+	}
+
+	chunks := splitRange(from, to, time.Duration(maxDays)*24*time.Hour)
+	results := make([][]Candle, len(chunks))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, historicalConcurrency)
+		ticker   = time.NewTicker(historicalRateInterval)
+	)
+	defer ticker.Stop()
+
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+	for i, chunk := range chunks {
+		if i > 0 {
+			<-ticker.C
+		}
+		sem <- struct{}{}
+		// no further chunks are dispatched once one has failed
+		if failed() {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(i int, chunk [2]time.Time) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			candles, err := c.historicalChunk(token, interval, chunk[0], chunk[1], continuous, oi)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			results[i] = candles
+		}(i, chunk)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return mergeCandles(results), nil
+}
+
+// historicalChunk fetches the candles of a single range, the range is sent in IST
+func (c *Client) historicalChunk(token uint32, interval string, from, to time.Time, continuous, oi bool) ([]Candle, error) {
+	params := url.Values{
+		"from":       {from.In(istLocation).Format(historicalTimeLayout)},
+		"to":         {to.In(istLocation).Format(historicalTimeLayout)},
+		"continuous": {boolParam(continuous)},
+		"oi":         {boolParam(oi)},
+	}
+	var data historicalData
+	if err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIHistoricalData, token, interval), params, nil, &data); err != nil {
+		return nil, err
+	}
+	return data.Candles, nil
+}
+
+// splitRange splits the range into consecutive chunks no longer than `window` - helper function
+func splitRange(from, to time.Time, window time.Duration) [][2]time.Time {
+	var chunks [][2]time.Time
+	for start := from; start.Before(to); {
+		end := start.Add(window)
+		if end.After(to) {
+			end = to
+		}
+		chunks = append(chunks, [2]time.Time{start, end})
+		start = end
+	}
+	return chunks
+}
+
+// mergeCandles flattens the chunks, drops duplicate timestamps and sorts by date - helper function
+func mergeCandles(chunks [][]Candle) []Candle {
+	seen := make(map[int64]bool)
+	var candles []Candle
+	for _, chunk := range chunks {
+		for _, candle := range chunk {
+			key := candle.Date.UnixNano()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			candles = append(candles, candle)
+		}
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Date.Before(candles[j].Date.Time)
+	})
+	return candles
+}
+
+// boolParam encodes a bool as `1` or `0` - helper function
+func boolParam(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
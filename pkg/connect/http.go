@@ -21,6 +21,12 @@ type HTTPClient interface {
 	GetClient() *httpClient
 }
 
+// HTTPStreamer is implemented by HTTP clients that can return a response with
+// an unread body. Clients that don't implement it are streamed from a buffered body.
+type HTTPStreamer interface {
+	DoStream(method, url string, params url.Values, headers http.Header) (*http.Response, error)
+}
+
 // httpClient is the default implementation of HTTPClient.
 type httpClient struct {
 	client *http.Client
@@ -80,11 +86,42 @@ func (h *httpClient) Do(method, rURL string, params url.Values, headers http.Hea
 // Do executes an HTTP request and returns the response.
 func (h *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Header) (HTTPResponse, error) {
 	var (
-		resp     = HTTPResponse{}
-		err      error
-		postBody io.Reader
+		resp = HTTPResponse{}
+		err  error
 	)
 
+	req, err := h.newRequest(method, rURL, reqBody, headers)
+	if err != nil {
+		return resp, err
+	}
+
+	r, err := h.client.Do(req)
+	if err != nil {
+		h.hLog.Printf("Request failed: %v", err)
+		return resp, NewError(NetworkError, "Request failed.", nil)
+	}
+
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.hLog.Printf("Unable to read response: %v", err)
+		return resp, NewError(DataError, "Error reading response.", nil)
+	}
+
+	resp.Response = r
+	resp.Body = body
+	if h.debug {
+		h.hLog.Printf("%s %s -- %d %v", method, req.URL.RequestURI(), resp.Response.StatusCode, req.Header)
+	}
+
+	return resp, nil
+}
+
+// newRequest prepares an HTTP request with the body or query string and headers.
+func (h *httpClient) newRequest(method, rURL string, reqBody []byte, headers http.Header) (*http.Request, error) {
+	var postBody io.Reader
+
 	// Encode POST / PUT params.
 	if method == http.MethodPost || method == http.MethodPut {
 		postBody = bytes.NewReader(reqBody)
@@ -93,7 +130,7 @@ func (h *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Hea
 	req, err := http.NewRequest(method, rURL, postBody)
 	if err != nil {
 		h.hLog.Printf("Request preparation failed: %v", err)
-		return resp, NewError(NetworkError, "Request preparation failed.", nil)
+		return nil, NewError(NetworkError, "Request preparation failed.", nil)
 	}
 
 	if headers != nil {
@@ -112,27 +149,42 @@ func (h *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Hea
 		req.URL.RawQuery = string(reqBody)
 	}
 
-	r, err := h.client.Do(req)
-	if err != nil {
-		h.hLog.Printf("Request failed: %v", err)
-		return resp, NewError(NetworkError, "Request failed.", nil)
+	return req, nil
+}
+
+// DoStream executes an HTTP request and returns the response with an unread body,
+// which the caller must close. Error envelopes are read and returned as Error.
+func (h *httpClient) DoStream(method, rURL string, params url.Values, headers http.Header) (*http.Response, error) {
+	if params == nil {
+		params = url.Values{}
 	}
 
-	defer r.Body.Close()
+	req, err := h.newRequest(method, rURL, []byte(params.Encode()), headers)
+	if err != nil {
+		return nil, err
+	}
 
-	body, err := ioutil.ReadAll(r.Body)
+	r, err := h.client.Do(req)
 	if err != nil {
-		h.hLog.Printf("Unable to read response: %v", err)
-		return resp, NewError(DataError, "Error reading response.", nil)
+		h.hLog.Printf("Request failed: %v", err)
+		return nil, NewError(NetworkError, "Request failed.", nil)
 	}
 
-	resp.Response = r
-	resp.Body = body
 	if h.debug {
-		h.hLog.Printf("%s %s -- %d %v", method, req.URL.RequestURI(), resp.Response.StatusCode, req.Header)
+		h.hLog.Printf("%s %s -- %d %v", method, req.URL.RequestURI(), r.StatusCode, req.Header)
 	}
 
-	return resp, nil
+	if r.StatusCode >= http.StatusBadRequest {
+		defer r.Body.Close()
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			h.hLog.Printf("Unable to read response: %v", err)
+			return nil, NewError(DataError, "Error reading response.", nil)
+		}
+		return nil, readEnvelope(HTTPResponse{Body: body, Response: r}, nil)
+	}
+
+	return r, nil
 }
 
 // DoEnvelope makes an HTTP request and parses the JSON response (fastglue envelop structure)
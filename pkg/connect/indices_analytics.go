@@ -0,0 +1,140 @@
+package mbconnect
+
+import "sort"
+
+// instrumentsInfoBatchSize is the maximum number of symbols per instruments info request
+const instrumentsInfoBatchSize = 500
+
+// IndexComposition is a struct that represents the constituents of an index
+// grouped by industry and resolved to their tradable instruments
+type IndexComposition struct {
+	Exchange     string
+	Name         string
+	Constituents []Index
+	Industries   map[string][]Index
+	Instruments  map[string]Instrument
+}
+
+// IndexChanges is a struct that represents the constituent changes of an index between two fetches
+type IndexChanges struct {
+	Added   []Index
+	Removed []Index
+}
+
+// IndexComposition gets the constituents of the index by `exchange` and `name`,
+// groups them by industry and resolves them to their instruments
+func (c *Client) IndexComposition(exchange, name string) (*IndexComposition, error) {
+	constituents, err := c.IndexInstruments(exchange, name)
+	if err != nil {
+		return nil, err
+	}
+	instruments, err := c.ResolveIndexInstruments(constituents)
+	if err != nil {
+		return nil, err
+	}
+	return &IndexComposition{
+		Exchange:     exchange,
+		Name:         name,
+		Constituents: constituents,
+		Industries:   GroupIndexByIndustry(constituents),
+		Instruments:  instruments,
+	}, nil
+}
+
+// ResolveIndexInstruments resolves the constituents to their instruments by
+// `EXCHANGE:TRADINGSYMBOL` using `InstrumentsInfoBySymbols`, in batches of
+// `instrumentsInfoBatchSize` symbols
+func (c *Client) ResolveIndexInstruments(constituents []Index) (map[string]Instrument, error) {
+	seen := make(map[string]bool, len(constituents))
+	symbols := make([]string, 0, len(constituents))
+	for _, constituent := range constituents {
+		symbol := constituent.Symbol()
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	instruments := make(map[string]Instrument, len(symbols))
+	for start := 0; start < len(symbols); start += instrumentsInfoBatchSize {
+		end := min(start+instrumentsInfoBatchSize, len(symbols))
+		batch, err := c.InstrumentsInfoBySymbols(symbols[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for symbol, instrument := range batch {
+			instruments[symbol] = instrument
+		}
+	}
+	return instruments, nil
+}
+
+// Symbol returns the constituent as `EXCHANGE:TRADINGSYMBOL`
+func (i Index) Symbol() string {
+	return i.Exchange + ":" + i.Tradingsymbol
+}
+
+// GroupIndexByIndustry groups the constituents by industry, sorted by tradingsymbol
+func GroupIndexByIndustry(constituents []Index) map[string][]Index {
+	industries := make(map[string][]Index)
+	for _, constituent := range constituents {
+		industries[constituent.Industry] = append(industries[constituent.Industry], constituent)
+	}
+	for _, group := range industries {
+		sortIndex(group)
+	}
+	return industries
+}
+
+// IndustryCounts returns the number of constituents per industry
+func (ic *IndexComposition) IndustryCounts() map[string]int {
+	counts := make(map[string]int, len(ic.Industries))
+	for industry, group := range ic.Industries {
+		counts[industry] = len(group)
+	}
+	return counts
+}
+
+// Instrument returns the tradable instrument of the constituent
+func (ic *IndexComposition) Instrument(constituent Index) (Instrument, bool) {
+	instrument, ok := ic.Instruments[constituent.Symbol()]
+	return instrument, ok
+}
+
+// DiffIndexConstituents compares two fetches of an index by `EXCHANGE:TRADINGSYMBOL`
+func DiffIndexConstituents(oldConstituents, newConstituents []Index) IndexChanges {
+	var changes IndexChanges
+
+	oldSymbols := make(map[string]bool, len(oldConstituents))
+	for _, constituent := range oldConstituents {
+		oldSymbols[constituent.Symbol()] = true
+	}
+	newSymbols := make(map[string]bool, len(newConstituents))
+	for _, constituent := range newConstituents {
+		newSymbols[constituent.Symbol()] = true
+		if !oldSymbols[constituent.Symbol()] {
+			changes.Added = append(changes.Added, constituent)
+		}
+	}
+	for _, constituent := range oldConstituents {
+		if !newSymbols[constituent.Symbol()] {
+			changes.Removed = append(changes.Removed, constituent)
+		}
+	}
+
+	sortIndex(changes.Added)
+	sortIndex(changes.Removed)
+	return changes
+}
+
+// IsEmpty reports whether the index constituents are unchanged
+func (ch IndexChanges) IsEmpty() bool {
+	return len(ch.Added) == 0 && len(ch.Removed) == 0
+}
+
+// sortIndex sorts the constituents by `EXCHANGE:TRADINGSYMBOL` - helper function
+func sortIndex(constituents []Index) {
+	sort.Slice(constituents, func(i, j int) bool {
+		return constituents[i].Symbol() < constituents[j].Symbol()
+	})
+}
@@ -0,0 +1,213 @@
+package mbconnect
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultMembershipConcurrency is the default number of concurrent `IndexInstruments` calls
+const defaultMembershipConcurrency = 8
+
+// IndexRef is a struct that identifies an index by `exchange` and `name`
+type IndexRef struct {
+	Exchange string
+	Name     string
+}
+
+// String returns the index as `EXCHANGE:NAME`
+func (r IndexRef) String() string {
+	return r.Exchange + ":" + r.Name
+}
+
+// IndexMembership is a cached graph of index constituents that answers which
+// indices a symbol belongs to. It is built from `IndicesAll` and
+// `IndexInstruments` and rebuilt once older than its ttl.
+type IndexMembership struct {
+	client      *Client
+	ttl         time.Duration
+	concurrency int
+
+	// refreshMu serializes rebuilds, so concurrent cache misses fetch once
+	refreshMu sync.Mutex
+
+	mu       sync.RWMutex
+	builtAt  time.Time
+	byIndex  map[IndexRef][]Index
+	bySymbol map[string][]IndexRef
+}
+
+// NewIndexMembership creates a new index membership graph, a ttl of 0 never expires the cache
+func NewIndexMembership(client *Client, ttl time.Duration) *IndexMembership {
+	return &IndexMembership{
+		client:      client,
+		ttl:         ttl,
+		concurrency: defaultMembershipConcurrency,
+	}
+}
+
+// SetConcurrency sets the number of concurrent `IndexInstruments` calls used to build the graph
+func (m *IndexMembership) SetConcurrency(concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.concurrency = concurrency
+}
+
+// Refresh rebuilds the membership graph
+func (m *IndexMembership) Refresh() error {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+	return m.refresh()
+}
+
+// refresh rebuilds the membership graph, `refreshMu` must be held
+func (m *IndexMembership) refresh() error {
+	exchangeIndices, err := m.client.IndicesAll()
+	if err != nil {
+		return err
+	}
+
+	refs := make(map[IndexRef]bool)
+	for exchange, indices := range exchangeIndices {
+		for _, index := range indices {
+			if index.Index != "" {
+				refs[IndexRef{Exchange: exchange, Name: index.Index}] = true
+			}
+		}
+	}
+
+	m.mu.RLock()
+	concurrency := m.concurrency
+	m.mu.RUnlock()
+
+	var (
+		wg       sync.WaitGroup
+		resultMu sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+		byIndex  = make(map[IndexRef][]Index, len(refs))
+	)
+	for ref := range refs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ref IndexRef) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			constituents, err := m.client.IndexInstruments(ref.Exchange, ref.Name)
+
+			resultMu.Lock()
+			defer resultMu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to get instruments of index %s: %w", ref, err)
+				}
+				return
+			}
+			byIndex[ref] = constituents
+		}(ref)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+
+	bySymbol := make(map[string][]IndexRef)
+	for ref, constituents := range byIndex {
+		for _, constituent := range constituents {
+			bySymbol[constituent.Symbol()] = append(bySymbol[constituent.Symbol()], ref)
+		}
+	}
+	for _, refs := range bySymbol {
+		sortIndexRefs(refs)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byIndex = byIndex
+	m.bySymbol = bySymbol
+	m.builtAt = time.Now()
+	return nil
+}
+
+// IndicesForSymbol returns the indices the symbol by `exchange` and `tradingsymbol` belongs to
+func (m *IndexMembership) IndicesForSymbol(exchange, tradingsymbol string) ([]IndexRef, error) {
+	if exchange == "" {
+		return nil, fmt.Errorf("`exchange` is required")
+	}
+	if tradingsymbol == "" {
+		return nil, fmt.Errorf("`tradingsymbol` is required")
+	}
+	if err := m.ensureFresh(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	refs := m.bySymbol[exchange+":"+tradingsymbol]
+	return append([]IndexRef(nil), refs...), nil
+}
+
+// CommonConstituents returns the constituents present in both indices
+func (m *IndexMembership) CommonConstituents(indexA, indexB IndexRef) ([]Index, error) {
+	if err := m.ensureFresh(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	constituentsA, ok := m.byIndex[indexA]
+	if !ok {
+		return nil, fmt.Errorf("unknown index %s", indexA)
+	}
+	constituentsB, ok := m.byIndex[indexB]
+	if !ok {
+		return nil, fmt.Errorf("unknown index %s", indexB)
+	}
+
+	symbolsB := make(map[string]bool, len(constituentsB))
+	for _, constituent := range constituentsB {
+		symbolsB[constituent.Symbol()] = true
+	}
+	var common []Index
+	for _, constituent := range constituentsA {
+		if symbolsB[constituent.Symbol()] {
+			common = append(common, constituent)
+		}
+	}
+	sortIndex(common)
+	return common, nil
+}
+
+// ensureFresh builds the graph if it was never built or is older than the ttl.
+// Callers that miss together wait for a single rebuild.
+func (m *IndexMembership) ensureFresh() error {
+	if !m.stale() {
+		return nil
+	}
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+	// Another caller may have rebuilt the graph while this one waited.
+	if !m.stale() {
+		return nil
+	}
+	return m.refresh()
+}
+
+// stale reports whether the graph was never built or is older than the ttl
+func (m *IndexMembership) stale() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.byIndex == nil || (m.ttl > 0 && time.Since(m.builtAt) > m.ttl)
+}
+
+// sortIndexRefs sorts the index refs by `EXCHANGE:NAME` - helper function
+func sortIndexRefs(refs []IndexRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+}
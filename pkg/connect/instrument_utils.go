@@ -0,0 +1,120 @@
+package mbconnect
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// tickEpsilon absorbs float error when dividing a price by the tick size,
+// so that 101.15 / 0.05 is treated as exactly 2023 ticks.
+const tickEpsilon = 1e-6
+
+// RoundToTick rounds the price to the nearest multiple of the tick size
+func (i Instrument) RoundToTick(price float64) float64 {
+	return i.roundToTick(price, math.Round)
+}
+
+// RoundUpToTick rounds the price up to the next multiple of the tick size
+func (i Instrument) RoundUpToTick(price float64) float64 {
+	return i.roundToTick(price, func(ticks float64) float64 {
+		return math.Ceil(ticks - tickEpsilon)
+	})
+}
+
+// RoundDownToTick rounds the price down to the previous multiple of the tick size
+func (i Instrument) RoundDownToTick(price float64) float64 {
+	return i.roundToTick(price, func(ticks float64) float64 {
+		return math.Floor(ticks + tickEpsilon)
+	})
+}
+
+// IsTickAligned reports whether the price is a multiple of the tick size
+func (i Instrument) IsTickAligned(price float64) bool {
+	if i.TickSize <= 0 {
+		return true
+	}
+	ticks := price / i.TickSize
+	return math.Abs(ticks-math.Round(ticks)) < tickEpsilon
+}
+
+// roundToTick rounds the price in ticks with the given rounding function and
+// trims float noise to the number of decimals in the tick size
+func (i Instrument) roundToTick(price float64, round func(float64) float64) float64 {
+	if i.TickSize <= 0 {
+		return price
+	}
+	ticks := round(price / i.TickSize)
+	return roundToDecimals(ticks*i.TickSize, tickDecimals(i.TickSize))
+}
+
+// LotsToQuantity converts a number of lots to a quantity
+func (i Instrument) LotsToQuantity(lots uint) uint {
+	return lots * i.lotSize()
+}
+
+// QuantityToLots converts a quantity to a number of whole lots, discarding any remainder
+func (i Instrument) QuantityToLots(quantity uint) uint {
+	return quantity / i.lotSize()
+}
+
+// RoundDownToLot rounds the quantity down to a multiple of the lot size
+func (i Instrument) RoundDownToLot(quantity uint) uint {
+	return i.QuantityToLots(quantity) * i.lotSize()
+}
+
+// ValidateQuantity returns an `InputError` if the quantity is zero or not a multiple of the lot size
+func (i Instrument) ValidateQuantity(quantity uint) error {
+	if quantity == 0 {
+		return NewError(InputError, "`quantity` is required", nil)
+	}
+	if quantity%i.lotSize() != 0 {
+		return NewError(InputError, fmt.Sprintf("`quantity` %d is not a multiple of lot size %d for %s", quantity, i.lotSize(), i.Tradingsymbol), nil)
+	}
+	return nil
+}
+
+// ValidatePrice returns an `InputError` if the price is not positive or not a multiple of the tick size
+func (i Instrument) ValidatePrice(price float64) error {
+	if price <= 0 {
+		return NewError(InputError, "`price` must be positive", nil)
+	}
+	if !i.IsTickAligned(price) {
+		return NewError(InputError, fmt.Sprintf("`price` %v is not a multiple of tick size %v for %s", price, i.TickSize, i.Tradingsymbol), nil)
+	}
+	return nil
+}
+
+// ClampToFreezeQuantity clamps the quantity to the largest multiple of the lot
+// size that does not exceed the exchange freeze quantity. A freeze quantity of
+// 0 means no limit.
+func (i Instrument) ClampToFreezeQuantity(quantity, freezeQuantity uint) uint {
+	if freezeQuantity == 0 || quantity <= freezeQuantity {
+		return quantity
+	}
+	return i.RoundDownToLot(freezeQuantity)
+}
+
+// lotSize returns the lot size, treating 0 as 1 for cash instruments
+func (i Instrument) lotSize() uint {
+	if i.LotSize == 0 {
+		return 1
+	}
+	return i.LotSize
+}
+
+// tickDecimals returns the number of decimals in the tick size - helper function
+func tickDecimals(tickSize float64) int {
+	s := strconv.FormatFloat(tickSize, 'f', -1, 64)
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		return len(s) - dot - 1
+	}
+	return 0
+}
+
+// roundToDecimals rounds the value to the number of decimals - helper function
+func roundToDecimals(value float64, decimals int) float64 {
+	pow := math.Pow(10, float64(decimals))
+	return math.Round(value*pow) / pow
+}
@@ -0,0 +1,111 @@
+package mbconnect
+
+import "testing"
+
+func TestRoundToTick(t *testing.T) {
+	tests := []struct {
+		tick    float64
+		price   float64
+		nearest float64
+		up      float64
+		down    float64
+	}{
+		{0.05, 101.15, 101.15, 101.15, 101.15},
+		{0.05, 101.151, 101.15, 101.2, 101.15},
+		{0.05, 101.174, 101.15, 101.2, 101.15},
+		{0.05, 101.176, 101.2, 101.2, 101.15},
+		{0.1, 0.1 + 0.2, 0.3, 0.3, 0.3},
+		{0.1, 24850.07, 24850.1, 24850.1, 24850},
+		{0.0025, 83.1237, 83.1225, 83.125, 83.1225},
+		{0.0025, 83.125, 83.125, 83.125, 83.125},
+		{1, 24853.5, 24854, 24854, 24853},
+	}
+	for _, tt := range tests {
+		i := Instrument{TickSize: tt.tick}
+		if got := i.RoundToTick(tt.price); got != tt.nearest {
+			t.Errorf("RoundToTick(%v) with tick %v = %v, want %v", tt.price, tt.tick, got, tt.nearest)
+		}
+		if got := i.RoundUpToTick(tt.price); got != tt.up {
+			t.Errorf("RoundUpToTick(%v) with tick %v = %v, want %v", tt.price, tt.tick, got, tt.up)
+		}
+		if got := i.RoundDownToTick(tt.price); got != tt.down {
+			t.Errorf("RoundDownToTick(%v) with tick %v = %v, want %v", tt.price, tt.tick, got, tt.down)
+		}
+	}
+}
+
+func TestIsTickAligned(t *testing.T) {
+	tests := []struct {
+		tick  float64
+		price float64
+		want  bool
+	}{
+		{0.05, 101.15, true},
+		{0.05, 101.151, false},
+		{0.05, 101.149, false},
+		{0.05, 0.1 + 0.2, true},
+		{0.05, 1.1 * 3, true},
+		{0.1, 0.1 + 0.2, true},
+		{0.1, 24850.05, false},
+		{0.0025, 83.1225, true},
+		{0.0025, 83.1226, false},
+		{0, 101.151, true},
+	}
+	for _, tt := range tests {
+		i := Instrument{TickSize: tt.tick}
+		if got := i.IsTickAligned(tt.price); got != tt.want {
+			t.Errorf("IsTickAligned(%v) with tick %v = %v, want %v", tt.price, tt.tick, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePrice(t *testing.T) {
+	i := Instrument{Tradingsymbol: "SBIN", TickSize: 0.05}
+	if err := i.ValidatePrice(101.15); err != nil {
+		t.Errorf("ValidatePrice(101.15) = %v, want nil", err)
+	}
+	for _, price := range []float64{101.151, 0, -1} {
+		err := i.ValidatePrice(price)
+		if e, ok := err.(Error); !ok || e.ErrorType != InputError {
+			t.Errorf("ValidatePrice(%v) = %v, want an InputError", price, err)
+		}
+	}
+}
+
+func TestQuantityHelpers(t *testing.T) {
+	i := Instrument{Tradingsymbol: "NIFTY24OCT25000CE", LotSize: 25}
+	if got := i.LotsToQuantity(3); got != 75 {
+		t.Errorf("LotsToQuantity(3) = %d, want 75", got)
+	}
+	if got := i.QuantityToLots(80); got != 3 {
+		t.Errorf("QuantityToLots(80) = %d, want 3", got)
+	}
+	if got := i.RoundDownToLot(80); got != 75 {
+		t.Errorf("RoundDownToLot(80) = %d, want 75", got)
+	}
+	if err := i.ValidateQuantity(75); err != nil {
+		t.Errorf("ValidateQuantity(75) = %v, want nil", err)
+	}
+	for _, quantity := range []uint{0, 80} {
+		if err := i.ValidateQuantity(quantity); err == nil {
+			t.Errorf("ValidateQuantity(%d) = nil, want an error", quantity)
+		}
+	}
+
+	tests := []struct{ quantity, freeze, want uint }{
+		{1000, 1800, 1000},
+		{4000, 1800, 1800},
+		{4000, 1810, 1800},
+		{4000, 0, 4000},
+	}
+	for _, tt := range tests {
+		if got := i.ClampToFreezeQuantity(tt.quantity, tt.freeze); got != tt.want {
+			t.Errorf("ClampToFreezeQuantity(%d, %d) = %d, want %d", tt.quantity, tt.freeze, got, tt.want)
+		}
+	}
+
+	cash := Instrument{Tradingsymbol: "SBIN"}
+	if err := cash.ValidateQuantity(7); err != nil {
+		t.Errorf("ValidateQuantity(7) with no lot size = %v, want nil", err)
+	}
+}
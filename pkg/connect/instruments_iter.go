@@ -0,0 +1,95 @@
+package mbconnect
+
+import (
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// GET /instruments/query - Iterate over instruments by query params
+//
+// The response is decoded as it streams in, so a full segment can be scanned
+// without holding every instrument in memory. Breaking out of the loop closes
+// the underlying response.
+func (c *Client) InstrumentsQueryIter(qp InstrumentsQueryParams) iter.Seq2[Instrument, error] {
+	return c.instrumentsQueryIter(makeQueryParams(qp))
+}
+
+// instrumentsQueryIter streams the instruments of a query - helper function
+func (c *Client) instrumentsQueryIter(params url.Values) iter.Seq2[Instrument, error] {
+	return func(yield func(Instrument, error) bool) {
+		r, err := c.doStream(http.MethodGet, URIInstrumentsQuery, params, nil)
+		if err != nil {
+			yield(Instrument{}, err)
+			return
+		}
+		defer r.Body.Close()
+
+		dec := json.NewDecoder(r.Body)
+		found, err := seekEnvelopeData(dec)
+		if err != nil {
+			yield(Instrument{}, err)
+			return
+		}
+		if !found {
+			return
+		}
+
+		for dec.More() {
+			var instrument Instrument
+			if err := dec.Decode(&instrument); err != nil {
+				yield(Instrument{}, NewError(DataError, "Error parsing response.", nil))
+				return
+			}
+			if !yield(instrument, nil) {
+				return
+			}
+		}
+
+		// A stream cut short ends without the closing bracket of the array.
+		if tok, err := dec.Token(); err != nil || tok != json.Delim(']') {
+			yield(Instrument{}, NewError(DataError, "Error parsing response.", nil))
+		}
+	}
+}
+
+// seekEnvelopeData advances the decoder into the `data` array of the envelope.
+// It returns false if the envelope has no data or the data is null.
+func seekEnvelopeData(dec *json.Decoder) (bool, error) {
+	parseErr := NewError(DataError, "Error parsing response.", nil)
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false, parseErr
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return false, parseErr
+		}
+		if key, _ := tok.(string); key != "data" {
+			// Skip the value of any other envelope key.
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return false, parseErr
+			}
+			continue
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return false, parseErr
+		}
+		switch tok {
+		case nil:
+			return false, nil
+		case json.Delim('['):
+			return true, nil
+		default:
+			return false, parseErr
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,190 @@
+package mbconnect
+
+import (
+	"iter"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// InstrumentsQueryBuilder builds instrument queries with multi-value (IN),
+// range and explicit zero-value filters. Equality filters are sent to the API
+// as repeated query params, ranges are applied client-side.
+type InstrumentsQueryBuilder struct {
+	exchanges        []string
+	tradingsymbols   []string
+	instrumentTokens []uint32
+	names            []string
+	expiries         []string
+	strikes          []float64
+	segments         []string
+	instrumentTypes  []string
+
+	minStrike, maxStrike *float64
+	fromExpiry, toExpiry string
+}
+
+// NewInstrumentsQuery creates a new instruments query builder
+func NewInstrumentsQuery() *InstrumentsQueryBuilder {
+	return &InstrumentsQueryBuilder{}
+}
+
+// Exchanges filters by any of the exchanges
+func (b *InstrumentsQueryBuilder) Exchanges(exchanges ...string) *InstrumentsQueryBuilder {
+	b.exchanges = append(b.exchanges, exchanges...)
+	return b
+}
+
+// Tradingsymbols filters by any of the tradingsymbols
+func (b *InstrumentsQueryBuilder) Tradingsymbols(tradingsymbols ...string) *InstrumentsQueryBuilder {
+	b.tradingsymbols = append(b.tradingsymbols, tradingsymbols...)
+	return b
+}
+
+// InstrumentTokens filters by any of the instrument tokens
+func (b *InstrumentsQueryBuilder) InstrumentTokens(tokens ...uint32) *InstrumentsQueryBuilder {
+	b.instrumentTokens = append(b.instrumentTokens, tokens...)
+	return b
+}
+
+// Names filters by any of the names
+func (b *InstrumentsQueryBuilder) Names(names ...string) *InstrumentsQueryBuilder {
+	b.names = append(b.names, names...)
+	return b
+}
+
+// Expiries filters by any of the expiries, an empty expiry matches non-expiring instruments
+func (b *InstrumentsQueryBuilder) Expiries(expiries ...string) *InstrumentsQueryBuilder {
+	b.expiries = append(b.expiries, expiries...)
+	return b
+}
+
+// Strikes filters by any of the strikes, 0 is sent explicitly and matches non-option instruments
+func (b *InstrumentsQueryBuilder) Strikes(strikes ...float64) *InstrumentsQueryBuilder {
+	b.strikes = append(b.strikes, strikes...)
+	return b
+}
+
+// Segments filters by any of the segments
+func (b *InstrumentsQueryBuilder) Segments(segments ...string) *InstrumentsQueryBuilder {
+	b.segments = append(b.segments, segments...)
+	return b
+}
+
+// InstrumentTypes filters by any of the instrument types
+func (b *InstrumentsQueryBuilder) InstrumentTypes(instrumentTypes ...string) *InstrumentsQueryBuilder {
+	b.instrumentTypes = append(b.instrumentTypes, instrumentTypes...)
+	return b
+}
+
+// StrikeRange filters by strikes between min and max, both inclusive
+func (b *InstrumentsQueryBuilder) StrikeRange(min, max float64) *InstrumentsQueryBuilder {
+	b.minStrike, b.maxStrike = &min, &max
+	return b
+}
+
+// ExpiryRange filters by expiries between from and to (`YYYY-MM-DD`), both
+// inclusive. An empty bound leaves that side of the range open.
+func (b *InstrumentsQueryBuilder) ExpiryRange(from, to string) *InstrumentsQueryBuilder {
+	b.fromExpiry, b.toExpiry = from, to
+	return b
+}
+
+// Params encodes the equality filters as repeated query params
+func (b *InstrumentsQueryBuilder) Params() url.Values {
+	params := url.Values{}
+	for _, exchange := range b.exchanges {
+		params.Add("exchange", exchange)
+	}
+	for _, tradingsymbol := range b.tradingsymbols {
+		params.Add("tradingsymbol", tradingsymbol)
+	}
+	for _, token := range b.instrumentTokens {
+		params.Add("instrument_token", strconv.FormatUint(uint64(token), 10))
+	}
+	for _, name := range b.names {
+		params.Add("name", name)
+	}
+	for _, expiry := range b.expiries {
+		params.Add("expiry", expiry)
+	}
+	for _, strike := range b.strikes {
+		params.Add("strike", strconv.FormatFloat(strike, 'f', -1, 64))
+	}
+	for _, segment := range b.segments {
+		params.Add("segment", segment)
+	}
+	for _, instrumentType := range b.instrumentTypes {
+		params.Add("instrument_type", instrumentType)
+	}
+	return params
+}
+
+// Matches reports whether the instrument satisfies every filter of the query
+func (b *InstrumentsQueryBuilder) Matches(instrument Instrument) bool {
+	if !matchAny(b.exchanges, instrument.Exchange) ||
+		!matchAny(b.tradingsymbols, instrument.Tradingsymbol) ||
+		!matchAny(b.instrumentTokens, instrument.InstrumentToken) ||
+		!matchAny(b.names, instrument.Name) ||
+		!matchAny(b.expiries, instrument.Expiry) ||
+		!matchAny(b.strikes, instrument.Strike) ||
+		!matchAny(b.segments, instrument.Segment) ||
+		!matchAny(b.instrumentTypes, instrument.InstrumentType) {
+		return false
+	}
+	if b.minStrike != nil && instrument.Strike < *b.minStrike {
+		return false
+	}
+	if b.maxStrike != nil && instrument.Strike > *b.maxStrike {
+		return false
+	}
+	if (b.fromExpiry != "" || b.toExpiry != "") && instrument.Expiry == "" {
+		return false
+	}
+	if b.fromExpiry != "" && instrument.Expiry < b.fromExpiry {
+		return false
+	}
+	if b.toExpiry != "" && instrument.Expiry > b.toExpiry {
+		return false
+	}
+	return true
+}
+
+// GET /instruments/query - Get instruments by a query builder
+//
+// Results are filtered client-side as well, so filters the API ignores or
+// cannot express (ranges, multiple values) still apply.
+func (c *Client) InstrumentsQueryBy(b *InstrumentsQueryBuilder) ([]Instrument, error) {
+	var instruments []Instrument
+	if err := c.doEnvelope(http.MethodGet, URIInstrumentsQuery, b.Params(), nil, &instruments); err != nil {
+		return nil, err
+	}
+	filtered := instruments[:0]
+	for _, instrument := range instruments {
+		if b.Matches(instrument) {
+			filtered = append(filtered, instrument)
+		}
+	}
+	return filtered, nil
+}
+
+// GET /instruments/query - Iterate over instruments by a query builder
+func (c *Client) InstrumentsQueryByIter(b *InstrumentsQueryBuilder) iter.Seq2[Instrument, error] {
+	return func(yield func(Instrument, error) bool) {
+		for instrument, err := range c.instrumentsQueryIter(b.Params()) {
+			if err != nil {
+				yield(Instrument{}, err)
+				return
+			}
+			if b.Matches(instrument) && !yield(instrument, nil) {
+				return
+			}
+		}
+	}
+}
+
+// matchAny reports whether the value is in the values, an empty filter matches all - helper function
+func matchAny[T comparable](values []T, value T) bool {
+	return len(values) == 0 || slices.Contains(values, value)
+}
@@ -0,0 +1,77 @@
+package mbconnect
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestInstrumentsQueryParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *InstrumentsQueryBuilder
+		want  url.Values
+	}{
+		{"empty", NewInstrumentsQuery(), url.Values{}},
+		{
+			"explicit zero strike",
+			NewInstrumentsQuery().Exchanges("NSE").Strikes(0),
+			url.Values{"exchange": {"NSE"}, "strike": {"0"}},
+		},
+		{
+			"instrument types",
+			NewInstrumentsQuery().Names("NIFTY").InstrumentTypes("CE", "PE"),
+			url.Values{"name": {"NIFTY"}, "instrument_type": {"CE", "PE"}},
+		},
+		{
+			"tokens and strikes",
+			NewInstrumentsQuery().InstrumentTokens(256265, 779521).Strikes(24500, 83.25),
+			url.Values{"instrument_token": {"256265", "779521"}, "strike": {"24500", "83.25"}},
+		},
+		{
+			"ranges are not sent",
+			NewInstrumentsQuery().Segments("NFO-OPT").StrikeRange(24000, 25000).ExpiryRange("2024-10-01", ""),
+			url.Values{"segment": {"NFO-OPT"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.query.Params(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Params() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInstrumentsQueryMatches(t *testing.T) {
+	equity := Instrument{Exchange: "NSE", Tradingsymbol: "INFY", Name: "INFOSYS", Segment: "NSE", InstrumentType: "EQ"}
+	future := Instrument{Exchange: "NFO", Tradingsymbol: "NIFTY24OCTFUT", Name: "NIFTY", Expiry: "2024-10-31", Segment: "NFO-FUT", InstrumentType: "FUT"}
+	call := Instrument{Exchange: "NFO", Tradingsymbol: "NIFTY24O1725000CE", Name: "NIFTY", Expiry: "2024-10-17", Strike: 25000, Segment: "NFO-OPT", InstrumentType: "CE"}
+	put := Instrument{Exchange: "NFO", Tradingsymbol: "NIFTY24NOV24000PE", Name: "NIFTY", Expiry: "2024-11-28", Strike: 24000, Segment: "NFO-OPT", InstrumentType: "PE"}
+
+	tests := []struct {
+		name       string
+		query      *InstrumentsQueryBuilder
+		instrument Instrument
+		want       bool
+	}{
+		{"empty query", NewInstrumentsQuery(), equity, true},
+		{"zero strike matches equity", NewInstrumentsQuery().Strikes(0), equity, true},
+		{"zero strike matches future", NewInstrumentsQuery().Strikes(0), future, true},
+		{"zero strike excludes option", NewInstrumentsQuery().Strikes(0), call, false},
+		{"type in list", NewInstrumentsQuery().InstrumentTypes("CE", "PE"), put, true},
+		{"type not in list", NewInstrumentsQuery().InstrumentTypes("CE", "PE"), future, false},
+		{"exchange and type", NewInstrumentsQuery().Exchanges("NSE").InstrumentTypes("CE"), call, false},
+		{"strike range inclusive", NewInstrumentsQuery().StrikeRange(24000, 25000), put, true},
+		{"strike range excludes", NewInstrumentsQuery().StrikeRange(24100, 25000), put, false},
+		{"expiry from open end", NewInstrumentsQuery().ExpiryRange("2024-10-31", ""), put, true},
+		{"expiry from excludes", NewInstrumentsQuery().ExpiryRange("2024-10-31", ""), call, false},
+		{"expiry to open start", NewInstrumentsQuery().ExpiryRange("", "2024-10-31"), future, true},
+		{"expiry to excludes", NewInstrumentsQuery().ExpiryRange("", "2024-10-31"), put, false},
+		{"expiry range excludes non-expiring", NewInstrumentsQuery().ExpiryRange("", "2024-10-31"), equity, false},
+		{"empty expiry matches non-expiring", NewInstrumentsQuery().Expiries(""), equity, true},
+	}
+	for _, tt := range tests {
+		if got := tt.query.Matches(tt.instrument); got != tt.want {
+			t.Errorf("%s: Matches(%s) = %v, want %v", tt.name, tt.instrument.Tradingsymbol, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package mbconnect
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// MarginOrder is a struct that represents an order to calculate margins and charges for
+type MarginOrder struct {
+	Exchange        string  `json:"exchange"`
+	Tradingsymbol   string  `json:"tradingsymbol"`
+	TransactionType string  `json:"transaction_type"`
+	Variety         string  `json:"variety"`
+	Product         string  `json:"product"`
+	OrderType       string  `json:"order_type"`
+	Quantity        uint    `json:"quantity"`
+	Price           float64 `json:"price,omitempty"`
+	TriggerPrice    float64 `json:"trigger_price,omitempty"`
+}
+
+// GST is a struct that represents the GST on the charges of an order
+type GST struct {
+	IGST  decimal.Decimal `json:"igst"`
+	CGST  decimal.Decimal `json:"cgst"`
+	SGST  decimal.Decimal `json:"sgst"`
+	Total decimal.Decimal `json:"total"`
+}
+
+// Charges is a struct that represents the breakdown of the charges of an order
+type Charges struct {
+	Brokerage              decimal.Decimal `json:"brokerage"`
+	TransactionTax         decimal.Decimal `json:"transaction_tax"`
+	TransactionTaxType     string          `json:"transaction_tax_type"`
+	ExchangeTurnoverCharge decimal.Decimal `json:"exchange_turnover_charge"`
+	SEBITurnoverCharge     decimal.Decimal `json:"sebi_turnover_charge"`
+	StampDuty              decimal.Decimal `json:"stamp_duty"`
+	GST                    GST             `json:"gst"`
+	Total                  decimal.Decimal `json:"total"`
+}
+
+// STT returns the securities transaction tax, zero if the tax is CTT
+func (c Charges) STT() decimal.Decimal {
+	if c.TransactionTaxType != "stt" {
+		return decimal.Zero
+	}
+	return c.TransactionTax
+}
+
+// Add returns the sum of the charges
+func (c Charges) Add(o Charges) Charges {
+	taxType := c.TransactionTaxType
+	if taxType == "" {
+		taxType = o.TransactionTaxType
+	}
+	return Charges{
+		Brokerage:              c.Brokerage.Add(o.Brokerage),
+		TransactionTax:         c.TransactionTax.Add(o.TransactionTax),
+		TransactionTaxType:     taxType,
+		ExchangeTurnoverCharge: c.ExchangeTurnoverCharge.Add(o.ExchangeTurnoverCharge),
+		SEBITurnoverCharge:     c.SEBITurnoverCharge.Add(o.SEBITurnoverCharge),
+		StampDuty:              c.StampDuty.Add(o.StampDuty),
+		GST: GST{
+			IGST:  c.GST.IGST.Add(o.GST.IGST),
+			CGST:  c.GST.CGST.Add(o.GST.CGST),
+			SGST:  c.GST.SGST.Add(o.GST.SGST),
+			Total: c.GST.Total.Add(o.GST.Total),
+		},
+		Total: c.Total.Add(o.Total),
+	}
+}
+
+// PnL is a struct that represents the realised and unrealised profit and loss
+type PnL struct {
+	Realised   decimal.Decimal `json:"realised"`
+	Unrealised decimal.Decimal `json:"unrealised"`
+}
+
+// OrderMargins is a struct that represents the margins required by an order and its charges
+type OrderMargins struct {
+	Type          string `json:"type"`
+	Tradingsymbol string `json:"tradingsymbol"`
+	Exchange      string `json:"exchange"`
+
+	SPAN          decimal.Decimal `json:"span"`
+	Exposure      decimal.Decimal `json:"exposure"`
+	OptionPremium decimal.Decimal `json:"option_premium"`
+	Additional    decimal.Decimal `json:"additional"`
+	BO            decimal.Decimal `json:"bo"`
+	Cash          decimal.Decimal `json:"cash"`
+	VAR           decimal.Decimal `json:"var"`
+	PnL           PnL             `json:"pnl"`
+	Leverage      decimal.Decimal `json:"leverage"`
+	Charges       Charges         `json:"charges"`
+	Total         decimal.Decimal `json:"total"`
+}
+
+// BasketMargins is a struct that represents the margins of a basket of orders.
+// `Initial` is the margin of the orders on their own and `Final` after
+// hedging benefits. `Charges` is the sum of the charges of the orders.
+type BasketMargins struct {
+	Initial OrderMargins   `json:"initial"`
+	Final   OrderMargins   `json:"final"`
+	Orders  []OrderMargins `json:"orders"`
+	Charges Charges        `json:"charges"`
+}
+
+// POST /margins/orders - Get the margins and charges of each order
+func (c *Client) OrderMargins(orders []MarginOrder) ([]OrderMargins, error) {
+	body, err := marginOrdersBody(orders)
+	if err != nil {
+		return nil, err
+	}
+	var margins []OrderMargins
+	err = c.doRawEnvelope(http.MethodPost, URIOrderMargins, body, jsonHeaders(), &margins)
+	return margins, err
+}
+
+// POST /margins/basket?consider_positions=true - Get the combined margins of a
+// basket of orders, optionally netted against the existing positions
+func (c *Client) BasketMargins(orders []MarginOrder, considerPositions bool) (BasketMargins, error) {
+	var margins BasketMargins
+	body, err := marginOrdersBody(orders)
+	if err != nil {
+		return margins, err
+	}
+	params := url.Values{}
+	params.Set("consider_positions", strconv.FormatBool(considerPositions))
+	uri := URIBasketMargins + "?" + params.Encode()
+	if err := c.doRawEnvelope(http.MethodPost, uri, body, jsonHeaders(), &margins); err != nil {
+		return margins, err
+	}
+
+	// Sum the orders' charges when the response has no basket charges.
+	if margins.Charges.Total.IsZero() {
+		for _, order := range margins.Orders {
+			margins.Charges = margins.Charges.Add(order.Charges)
+		}
+	}
+	return margins, nil
+}
+
+// Validate returns an `InputError` if the order is missing a field required for margins
+func (o MarginOrder) Validate() error {
+	if o.Exchange == "" {
+		return NewError(InputError, "`exchange` is required", nil)
+	}
+	if o.Tradingsymbol == "" {
+		return NewError(InputError, "`tradingsymbol` is required", nil)
+	}
+	if o.TransactionType != TransactionTypeBuy && o.TransactionType != TransactionTypeSell {
+		return NewError(InputError, fmt.Sprintf("invalid `transaction_type` %q", o.TransactionType), nil)
+	}
+	if err := validateVariety(o.Variety); err != nil {
+		return err
+	}
+	if o.Product == "" {
+		return NewError(InputError, "`product` is required", nil)
+	}
+	if o.OrderType == "" {
+		return NewError(InputError, "`order_type` is required", nil)
+	}
+	if o.Quantity == 0 {
+		return NewError(InputError, "`quantity` is required", nil)
+	}
+	return nil
+}
+
+// MarginOrderFromParams converts order parameters to a margin order
+func MarginOrderFromParams(variety string, params OrderParams) MarginOrder {
+	return MarginOrder{
+		Exchange:        params.Exchange,
+		Tradingsymbol:   params.Tradingsymbol,
+		TransactionType: params.TransactionType,
+		Variety:         variety,
+		Product:         params.Product,
+		OrderType:       params.OrderType,
+		Quantity:        params.Quantity,
+		Price:           params.Price,
+		TriggerPrice:    params.TriggerPrice,
+	}
+}
+
+// marginOrdersBody validates and encodes the orders as a JSON body - helper function
+func marginOrdersBody(orders []MarginOrder) ([]byte, error) {
+	if len(orders) == 0 {
+		return nil, NewError(InputError, "`orders` are required", nil)
+	}
+	for i, order := range orders {
+		if err := order.Validate(); err != nil {
+			return nil, NewError(InputError, fmt.Sprintf("order %d: %v", i, err), nil)
+		}
+	}
+	body, err := json.Marshal(orders)
+	if err != nil {
+		return nil, NewError(InputError, "failed to encode `orders`", nil)
+	}
+	return body, nil
+}
+
+// jsonHeaders returns the headers of a JSON request body - helper function
+func jsonHeaders() http.Header {
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	return headers
+}
@@ -0,0 +1,434 @@
+package mbconnect
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// Order varieties
+const (
+	VarietyRegular = "regular"
+	VarietyAMO     = "amo"
+	VarietyCO      = "co"
+	VarietyIceberg = "iceberg"
+)
+
+// Products
+const (
+	ProductCNC  = "CNC"
+	ProductNRML = "NRML"
+	ProductMIS  = "MIS"
+)
+
+// Order types
+const (
+	OrderTypeMarket = "MARKET"
+	OrderTypeLimit  = "LIMIT"
+	OrderTypeSL     = "SL"
+	OrderTypeSLM    = "SL-M"
+)
+
+// Transaction types
+const (
+	TransactionTypeBuy  = "BUY"
+	TransactionTypeSell = "SELL"
+)
+
+// Validities
+const (
+	ValidityDay = "DAY"
+	ValidityIOC = "IOC"
+	ValidityTTL = "TTL"
+)
+
+// Iceberg and tag limits
+const (
+	icebergMinLegs = 2
+	icebergMaxLegs = 10
+	tagMaxLength   = 20
+)
+
+// tagPattern is the allowed format of an order tag
+var tagPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]*$`)
+
+// OrderParams is a struct that represents the parameters of an order.
+// Prices and quantities left at zero are not sent.
+type OrderParams struct {
+	Exchange          string
+	Tradingsymbol     string
+	TransactionType   string
+	Quantity          uint
+	DisclosedQuantity uint
+	Price             float64
+	TriggerPrice      float64
+	Product           string
+	OrderType         string
+	Validity          string
+	ValidityTTL       uint
+	IcebergLegs       uint
+	IcebergQuantity   uint
+	Tag               string
+}
+
+// OrderResponse is a struct that represents the response of an order request
+type OrderResponse struct {
+	OrderID string `json:"order_id"`
+}
+
+// POST /orders/:variety - Place an order
+func (c *Client) PlaceOrder(variety string, params OrderParams) (OrderResponse, error) {
+	var resp OrderResponse
+	if err := params.Validate(variety); err != nil {
+		return resp, err
+	}
+	err := c.doEnvelope(http.MethodPost, fmt.Sprintf(URIPlaceOrder, variety), params.values(), nil, &resp)
+	return resp, err
+}
+
+// PUT /orders/:variety/:order_id - Modify an open order, only the quantity,
+// prices, order type and validity of `params` are sent
+func (c *Client) ModifyOrder(variety, orderID string, params OrderParams) (OrderResponse, error) {
+	var resp OrderResponse
+	if err := params.validateModify(variety, orderID); err != nil {
+		return resp, err
+	}
+	err := c.doEnvelope(http.MethodPut, fmt.Sprintf(URIModifyOrder, variety, orderID), params.modifyValues(), nil, &resp)
+	return resp, err
+}
+
+// DELETE /orders/:variety/:order_id - Cancel an open order
+func (c *Client) CancelOrder(variety, orderID string) (OrderResponse, error) {
+	return c.cancelOrder(variety, orderID, "")
+}
+
+// DELETE /orders/:variety/:order_id?parent_order_id= - Exit a cover order,
+// `orderID` is the second leg of the order and `parentOrderID` the first
+func (c *Client) ExitOrder(variety, orderID, parentOrderID string) (OrderResponse, error) {
+	if parentOrderID == "" && variety == VarietyCO {
+		return OrderResponse{}, NewError(InputError, "`parent_order_id` is required to exit a cover order", nil)
+	}
+	return c.cancelOrder(variety, orderID, parentOrderID)
+}
+
+// cancelOrder cancels or exits an order - helper function
+func (c *Client) cancelOrder(variety, orderID, parentOrderID string) (OrderResponse, error) {
+	var resp OrderResponse
+	if err := validateVariety(variety); err != nil {
+		return resp, err
+	}
+	if orderID == "" {
+		return resp, NewError(InputError, "`order_id` is required", nil)
+	}
+	params := url.Values{}
+	if parentOrderID != "" {
+		params.Set("parent_order_id", parentOrderID)
+	}
+	err := c.doEnvelope(http.MethodDelete, fmt.Sprintf(URICancelOrder, variety, orderID), params, nil, &resp)
+	return resp, err
+}
+
+// Validate returns an `InputError` if the parameters are not a valid order of the variety
+func (p OrderParams) Validate(variety string) error {
+	if err := validateVariety(variety); err != nil {
+		return err
+	}
+	if p.Exchange == "" {
+		return NewError(InputError, "`exchange` is required", nil)
+	}
+	if p.Tradingsymbol == "" {
+		return NewError(InputError, "`tradingsymbol` is required", nil)
+	}
+	if p.TransactionType != TransactionTypeBuy && p.TransactionType != TransactionTypeSell {
+		return NewError(InputError, fmt.Sprintf("invalid `transaction_type` %q", p.TransactionType), nil)
+	}
+	if p.Quantity == 0 {
+		return NewError(InputError, "`quantity` is required", nil)
+	}
+	if p.DisclosedQuantity > p.Quantity {
+		return NewError(InputError, "`disclosed_quantity` can't exceed `quantity`", nil)
+	}
+	switch p.Product {
+	case ProductCNC, ProductNRML, ProductMIS:
+	default:
+		return NewError(InputError, fmt.Sprintf("invalid `product` %q", p.Product), nil)
+	}
+	if err := p.validatePrices(); err != nil {
+		return err
+	}
+	if err := p.validateValidity(); err != nil {
+		return err
+	}
+	if len(p.Tag) > tagMaxLength || !tagPattern.MatchString(p.Tag) {
+		return NewError(InputError, fmt.Sprintf("`tag` must be up to %d alphanumeric characters", tagMaxLength), nil)
+	}
+
+	switch variety {
+	case VarietyCO:
+		if p.Product != ProductMIS {
+			return NewError(InputError, "cover orders must use the `MIS` product", nil)
+		}
+		if p.OrderType != OrderTypeMarket && p.OrderType != OrderTypeLimit {
+			return NewError(InputError, "cover orders must be `MARKET` or `LIMIT` orders", nil)
+		}
+		if p.TriggerPrice <= 0 {
+			return NewError(InputError, "`trigger_price` is required for cover orders", nil)
+		}
+	case VarietyIceberg:
+		if p.IcebergLegs < icebergMinLegs || p.IcebergLegs > icebergMaxLegs {
+			return NewError(InputError, fmt.Sprintf("`iceberg_legs` must be between %d and %d", icebergMinLegs, icebergMaxLegs), nil)
+		}
+		if p.IcebergQuantity == 0 || p.IcebergQuantity*p.IcebergLegs < p.Quantity {
+			return NewError(InputError, "`iceberg_quantity` times `iceberg_legs` must cover `quantity`", nil)
+		}
+	}
+	return nil
+}
+
+// validatePrices checks the price and trigger price required by the order type
+func (p OrderParams) validatePrices() error {
+	switch p.OrderType {
+	case OrderTypeMarket:
+	case OrderTypeLimit:
+		if p.Price <= 0 {
+			return NewError(InputError, "`price` is required for `LIMIT` orders", nil)
+		}
+	case OrderTypeSL:
+		if p.Price <= 0 || p.TriggerPrice <= 0 {
+			return NewError(InputError, "`price` and `trigger_price` are required for `SL` orders", nil)
+		}
+	case OrderTypeSLM:
+		if p.TriggerPrice <= 0 {
+			return NewError(InputError, "`trigger_price` is required for `SL-M` orders", nil)
+		}
+	default:
+		return NewError(InputError, fmt.Sprintf("invalid `order_type` %q", p.OrderType), nil)
+	}
+	if p.Price < 0 || p.TriggerPrice < 0 {
+		return NewError(InputError, "prices can't be negative", nil)
+	}
+	return nil
+}
+
+// validateValidity checks the validity and its TTL, an empty validity is `DAY`
+func (p OrderParams) validateValidity() error {
+	switch p.Validity {
+	case "", ValidityDay, ValidityIOC:
+		if p.ValidityTTL != 0 {
+			return NewError(InputError, "`validity_ttl` is only allowed with `TTL` validity", nil)
+		}
+	case ValidityTTL:
+		if p.ValidityTTL == 0 {
+			return NewError(InputError, "`validity_ttl` is required for `TTL` validity", nil)
+		}
+	default:
+		return NewError(InputError, fmt.Sprintf("invalid `validity` %q", p.Validity), nil)
+	}
+	return nil
+}
+
+// validateModify checks the fields that can be modified
+func (p OrderParams) validateModify(variety, orderID string) error {
+	if err := validateVariety(variety); err != nil {
+		return err
+	}
+	if orderID == "" {
+		return NewError(InputError, "`order_id` is required", nil)
+	}
+	if p.OrderType != "" {
+		if err := p.validatePrices(); err != nil {
+			return err
+		}
+	}
+	if p.Price < 0 || p.TriggerPrice < 0 {
+		return NewError(InputError, "prices can't be negative", nil)
+	}
+	if p.Quantity > 0 && p.DisclosedQuantity > p.Quantity {
+		return NewError(InputError, "`disclosed_quantity` can't exceed `quantity`", nil)
+	}
+	return p.validateValidity()
+}
+
+// values encodes the order parameters
+func (p OrderParams) values() url.Values {
+	params := url.Values{}
+	params.Set("exchange", p.Exchange)
+	params.Set("tradingsymbol", p.Tradingsymbol)
+	params.Set("transaction_type", p.TransactionType)
+	params.Set("product", p.Product)
+	params.Set("validity", p.Validity)
+	if p.Validity == "" {
+		params.Set("validity", ValidityDay)
+	}
+	setUint(params, "validity_ttl", p.ValidityTTL)
+	setUint(params, "iceberg_legs", p.IcebergLegs)
+	setUint(params, "iceberg_quantity", p.IcebergQuantity)
+	if p.Tag != "" {
+		params.Set("tag", p.Tag)
+	}
+	p.setAmendable(params)
+	return params
+}
+
+// modifyValues encodes the order parameters that can be modified
+func (p OrderParams) modifyValues() url.Values {
+	params := url.Values{}
+	if p.Validity != "" {
+		params.Set("validity", p.Validity)
+	}
+	setUint(params, "validity_ttl", p.ValidityTTL)
+	p.setAmendable(params)
+	return params
+}
+
+// setAmendable sets the fields shared by placing and modifying an order
+func (p OrderParams) setAmendable(params url.Values) {
+	if p.OrderType != "" {
+		params.Set("order_type", p.OrderType)
+	}
+	setUint(params, "quantity", p.Quantity)
+	setUint(params, "disclosed_quantity", p.DisclosedQuantity)
+	if p.Price > 0 {
+		params.Set("price", strconv.FormatFloat(p.Price, 'f', -1, 64))
+	}
+	if p.TriggerPrice > 0 {
+		params.Set("trigger_price", strconv.FormatFloat(p.TriggerPrice, 'f', -1, 64))
+	}
+}
+
+// validateVariety returns an `InputError` for an unknown variety - helper function
+func validateVariety(variety string) error {
+	switch variety {
+	case VarietyRegular, VarietyAMO, VarietyCO, VarietyIceberg:
+		return nil
+	case "":
+		return NewError(InputError, "`variety` is required", nil)
+	default:
+		return NewError(InputError, fmt.Sprintf("invalid `variety` %q", variety), nil)
+	}
+}
+
+// setUint sets a non-zero unsigned param - helper function
+func setUint(params url.Values, key string, v uint) {
+	if v > 0 {
+		params.Set(key, strconv.FormatUint(uint64(v), 10))
+	}
+}
+
+// Order statuses
+const (
+	OrderStatusOpen              = "OPEN"
+	OrderStatusComplete          = "COMPLETE"
+	OrderStatusCancelled         = "CANCELLED"
+	OrderStatusRejected          = "REJECTED"
+	OrderStatusTriggerPending    = "TRIGGER PENDING"
+	OrderStatusReceived          = "PUT ORDER REQ RECEIVED"
+	OrderStatusValidationPending = "VALIDATION PENDING"
+	OrderStatusOpenPending       = "OPEN PENDING"
+	OrderStatusModifyPending     = "MODIFY PENDING"
+	OrderStatusCancelPending     = "CANCEL PENDING"
+)
+
+// Order is a struct that represents a state of an order, money fields are decimals
+type Order struct {
+	AccountID        string `json:"account_id"`
+	PlacedBy         string `json:"placed_by"`
+	OrderID          string `json:"order_id"`
+	ExchangeOrderID  string `json:"exchange_order_id"`
+	ParentOrderID    string `json:"parent_order_id"`
+	Status           string `json:"status"`
+	StatusMessage    string `json:"status_message"`
+	StatusMessageRaw string `json:"status_message_raw"`
+
+	OrderTimestamp          Time `json:"order_timestamp"`
+	ExchangeUpdateTimestamp Time `json:"exchange_update_timestamp"`
+	ExchangeTimestamp       Time `json:"exchange_timestamp"`
+
+	Variety         string `json:"variety"`
+	Modified        bool   `json:"modified"`
+	Exchange        string `json:"exchange"`
+	Tradingsymbol   string `json:"tradingsymbol"`
+	InstrumentToken uint32 `json:"instrument_token"`
+	OrderType       string `json:"order_type"`
+	TransactionType string `json:"transaction_type"`
+	Validity        string `json:"validity"`
+	ValidityTTL     uint   `json:"validity_ttl"`
+	Product         string `json:"product"`
+
+	Quantity          uint            `json:"quantity"`
+	DisclosedQuantity uint            `json:"disclosed_quantity"`
+	Price             decimal.Decimal `json:"price"`
+	TriggerPrice      decimal.Decimal `json:"trigger_price"`
+	AveragePrice      decimal.Decimal `json:"average_price"`
+	FilledQuantity    uint            `json:"filled_quantity"`
+	PendingQuantity   uint            `json:"pending_quantity"`
+	CancelledQuantity uint            `json:"cancelled_quantity"`
+
+	Tag  string   `json:"tag"`
+	Tags []string `json:"tags"`
+}
+
+// IsOpen reports whether the order can still be filled, modified or cancelled
+func (o Order) IsOpen() bool {
+	switch o.Status {
+	case OrderStatusComplete, OrderStatusCancelled, OrderStatusRejected:
+		return false
+	default:
+		return true
+	}
+}
+
+// Trade is a struct that represents an individual fill of an order
+type Trade struct {
+	TradeID           string          `json:"trade_id"`
+	OrderID           string          `json:"order_id"`
+	ExchangeOrderID   string          `json:"exchange_order_id"`
+	Exchange          string          `json:"exchange"`
+	Tradingsymbol     string          `json:"tradingsymbol"`
+	InstrumentToken   uint32          `json:"instrument_token"`
+	Product           string          `json:"product"`
+	TransactionType   string          `json:"transaction_type"`
+	Quantity          uint            `json:"quantity"`
+	AveragePrice      decimal.Decimal `json:"average_price"`
+	FillTimestamp     Time            `json:"fill_timestamp"`
+	OrderTimestamp    Time            `json:"order_timestamp"`
+	ExchangeTimestamp Time            `json:"exchange_timestamp"`
+}
+
+// GET /orders - Get the orders of the day
+func (c *Client) Orders() ([]Order, error) {
+	var orders []Order
+	err := c.doEnvelope(http.MethodGet, URIGetOrders, nil, nil, &orders)
+	return orders, err
+}
+
+// GET /orders/:order_id - Get the states of an order, oldest first
+func (c *Client) OrderHistory(orderID string) ([]Order, error) {
+	if orderID == "" {
+		return nil, NewError(InputError, "`order_id` is required", nil)
+	}
+	var history []Order
+	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetOrderHistory, orderID), nil, nil, &history)
+	return history, err
+}
+
+// GET /trades - Get the trades of the day
+func (c *Client) Trades() ([]Trade, error) {
+	var trades []Trade
+	err := c.doEnvelope(http.MethodGet, URIGetTrades, nil, nil, &trades)
+	return trades, err
+}
+
+// GET /orders/:order_id/trades - Get the trades of an order
+func (c *Client) OrderTrades(orderID string) ([]Trade, error) {
+	if orderID == "" {
+		return nil, NewError(InputError, "`order_id` is required", nil)
+	}
+	var trades []Trade
+	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetOrderTrades, orderID), nil, nil, &trades)
+	return trades, err
+}
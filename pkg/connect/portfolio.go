@@ -0,0 +1,92 @@
+package mbconnect
+
+import (
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// Position is a struct that represents an open or closed position, money fields are decimals
+type Position struct {
+	Tradingsymbol   string `json:"tradingsymbol"`
+	Exchange        string `json:"exchange"`
+	InstrumentToken uint32 `json:"instrument_token"`
+	Product         string `json:"product"`
+
+	Quantity          int             `json:"quantity"`
+	OvernightQuantity int             `json:"overnight_quantity"`
+	Multiplier        decimal.Decimal `json:"multiplier"`
+
+	AveragePrice decimal.Decimal `json:"average_price"`
+	ClosePrice   decimal.Decimal `json:"close_price"`
+	LastPrice    decimal.Decimal `json:"last_price"`
+	Value        decimal.Decimal `json:"value"`
+	PnL          decimal.Decimal `json:"pnl"`
+	M2M          decimal.Decimal `json:"m2m"`
+	Unrealised   decimal.Decimal `json:"unrealised"`
+	Realised     decimal.Decimal `json:"realised"`
+
+	BuyQuantity int             `json:"buy_quantity"`
+	BuyPrice    decimal.Decimal `json:"buy_price"`
+	BuyValue    decimal.Decimal `json:"buy_value"`
+	BuyM2MValue decimal.Decimal `json:"buy_m2m"`
+
+	SellQuantity int             `json:"sell_quantity"`
+	SellPrice    decimal.Decimal `json:"sell_price"`
+	SellValue    decimal.Decimal `json:"sell_value"`
+	SellM2MValue decimal.Decimal `json:"sell_m2m"`
+
+	DayBuyQuantity int             `json:"day_buy_quantity"`
+	DayBuyPrice    decimal.Decimal `json:"day_buy_price"`
+	DayBuyValue    decimal.Decimal `json:"day_buy_value"`
+
+	DaySellQuantity int             `json:"day_sell_quantity"`
+	DaySellPrice    decimal.Decimal `json:"day_sell_price"`
+	DaySellValue    decimal.Decimal `json:"day_sell_value"`
+}
+
+// Positions is a struct that represents the net positions and the positions taken during the day
+type Positions struct {
+	Net []Position `json:"net"`
+	Day []Position `json:"day"`
+}
+
+// Holding is a struct that represents a delivery holding, money fields are decimals
+type Holding struct {
+	Tradingsymbol   string `json:"tradingsymbol"`
+	Exchange        string `json:"exchange"`
+	InstrumentToken uint32 `json:"instrument_token"`
+	ISIN            string `json:"isin"`
+	Product         string `json:"product"`
+
+	Quantity           int    `json:"quantity"`
+	UsedQuantity       int    `json:"used_quantity"`
+	T1Quantity         int    `json:"t1_quantity"`
+	RealisedQuantity   int    `json:"realised_quantity"`
+	OpeningQuantity    int    `json:"opening_quantity"`
+	CollateralQuantity int    `json:"collateral_quantity"`
+	AuthorisedQuantity int    `json:"authorised_quantity"`
+	CollateralType     string `json:"collateral_type"`
+	Discrepancy        bool   `json:"discrepancy"`
+
+	AveragePrice        decimal.Decimal `json:"average_price"`
+	LastPrice           decimal.Decimal `json:"last_price"`
+	ClosePrice          decimal.Decimal `json:"close_price"`
+	PnL                 decimal.Decimal `json:"pnl"`
+	DayChange           decimal.Decimal `json:"day_change"`
+	DayChangePercentage decimal.Decimal `json:"day_change_percentage"`
+}
+
+// GET /portfolio/positions - Get the net and day positions
+func (c *Client) Positions() (Positions, error) {
+	var positions Positions
+	err := c.doEnvelope(http.MethodGet, URIGetPositions, nil, nil, &positions)
+	return positions, err
+}
+
+// GET /portfolio/holdings - Get the delivery holdings
+func (c *Client) Holdings() ([]Holding, error) {
+	var holdings []Holding
+	err := c.doEnvelope(http.MethodGet, URIGetHoldings, nil, nil, &holdings)
+	return holdings, err
+}
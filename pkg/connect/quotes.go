@@ -0,0 +1,107 @@
+package mbconnect
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Maximum number of instruments per request
+const (
+	quoteBatchSize = 500
+	ltpBatchSize   = 1000
+	ohlcBatchSize  = 1000
+)
+
+// OHLC is a struct that represents the open, high, low and close prices
+type OHLC struct {
+	Open  float64 `json:"open"`
+	High  float64 `json:"high"`
+	Low   float64 `json:"low"`
+	Close float64 `json:"close"`
+}
+
+// DepthItem is a struct that represents a price level of the market depth
+type DepthItem struct {
+	Price    float64 `json:"price"`
+	Quantity uint32  `json:"quantity"`
+	Orders   uint32  `json:"orders"`
+}
+
+// Depth is a struct that represents the buy and sell market depth
+type Depth struct {
+	Buy  []DepthItem `json:"buy"`
+	Sell []DepthItem `json:"sell"`
+}
+
+// Quote is a struct that represents the full market quote of an instrument
+type Quote struct {
+	InstrumentToken   uint32  `json:"instrument_token"`
+	Timestamp         Time    `json:"timestamp"`
+	LastPrice         float64 `json:"last_price"`
+	LastQuantity      uint32  `json:"last_quantity"`
+	LastTradeTime     Time    `json:"last_trade_time"`
+	AveragePrice      float64 `json:"average_price"`
+	Volume            uint32  `json:"volume"`
+	BuyQuantity       uint32  `json:"buy_quantity"`
+	SellQuantity      uint32  `json:"sell_quantity"`
+	OHLC              OHLC    `json:"ohlc"`
+	NetChange         float64 `json:"net_change"`
+	OI                float64 `json:"oi"`
+	OIDayHigh         float64 `json:"oi_day_high"`
+	OIDayLow          float64 `json:"oi_day_low"`
+	LowerCircuitLimit float64 `json:"lower_circuit_limit"`
+	UpperCircuitLimit float64 `json:"upper_circuit_limit"`
+	Depth             Depth   `json:"depth"`
+}
+
+// QuoteLTP is a struct that represents the last traded price of an instrument
+type QuoteLTP struct {
+	InstrumentToken uint32  `json:"instrument_token"`
+	LastPrice       float64 `json:"last_price"`
+}
+
+// QuoteOHLC is a struct that represents the OHLC and last traded price of an instrument
+type QuoteOHLC struct {
+	InstrumentToken uint32  `json:"instrument_token"`
+	LastPrice       float64 `json:"last_price"`
+	OHLC            OHLC    `json:"ohlc"`
+}
+
+// GET /quote?i=NSE:INFY&i=256265 - Get full market quotes by `EXCHANGE:TRADINGSYMBOL` or instrument token
+func (c *Client) Quote(instruments []string) (map[string]Quote, error) {
+	return getQuotes[Quote](c, URIQuote, instruments, quoteBatchSize)
+}
+
+// GET /quote/ltp?i=NSE:INFY&i=256265 - Get last traded prices by `EXCHANGE:TRADINGSYMBOL` or instrument token
+func (c *Client) LTP(instruments []string) (map[string]QuoteLTP, error) {
+	return getQuotes[QuoteLTP](c, URIQuoteLTP, instruments, ltpBatchSize)
+}
+
+// GET /quote/ohlc?i=NSE:INFY&i=256265 - Get OHLC quotes by `EXCHANGE:TRADINGSYMBOL` or instrument token
+func (c *Client) OHLC(instruments []string) (map[string]QuoteOHLC, error) {
+	return getQuotes[QuoteOHLC](c, URIQuoteOHLC, instruments, ohlcBatchSize)
+}
+
+// getQuotes fetches the quotes in batches of `batchSize` and merges them - helper function
+func getQuotes[T any](c *Client, uri string, instruments []string, batchSize int) (map[string]T, error) {
+	if len(instruments) == 0 {
+		return nil, fmt.Errorf("`instruments` are required")
+	}
+	quotes := make(map[string]T, len(instruments))
+	for start := 0; start < len(instruments); start += batchSize {
+		end := min(start+batchSize, len(instruments))
+		params := url.Values{}
+		for _, instrument := range instruments[start:end] {
+			params.Add("i", instrument)
+		}
+		var batch map[string]T
+		if err := c.doEnvelope(http.MethodGet, uri, params, nil, &batch); err != nil {
+			return nil, err
+		}
+		for key, quote := range batch {
+			quotes[key] = quote
+		}
+	}
+	return quotes, nil
+}
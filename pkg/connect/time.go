@@ -0,0 +1,47 @@
+package mbconnect
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Time is a time.Time that decodes the timestamp formats returned by the API
+type Time struct {
+	time.Time
+}
+
+// timeLayouts are the timestamp layouts returned by the API
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05-0700",
+	time.RFC3339,
+	"2006-01-02",
+}
+
+// UnmarshalJSON parses a JSON timestamp, `null` and empty strings decode to the zero time
+func (t *Time) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		t.Time = time.Time{}
+		return nil
+	}
+	for _, layout := range timeLayouts {
+		if parsed, err := time.ParseInLocation(layout, s, istLocation); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown time format %q", s)
+}
+
+// MarshalJSON encodes the time as RFC3339, the zero time encodes as `null`
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + t.Format(time.RFC3339) + `"`), nil
+}
+
+// istLocation is the exchange timezone (Asia/Kolkata)
+var istLocation = time.FixedZone("IST", 5*60*60+30*60)
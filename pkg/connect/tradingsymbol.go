@@ -0,0 +1,265 @@
+package mbconnect
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Instrument types encoded in derivative tradingsymbols
+const (
+	InstrumentTypeFUT = "FUT"
+	InstrumentTypeCE  = "CE"
+	InstrumentTypePE  = "PE"
+)
+
+// expiryLayout is the date layout of `Instrument.Expiry`
+const expiryLayout = "2006-01-02"
+
+// Tradingsymbol is a struct that represents a parsed derivative tradingsymbol.
+// For monthly contracts `Day` is 0, as the symbol only encodes year and month.
+type Tradingsymbol struct {
+	Name           string
+	Year           int
+	Month          time.Month
+	Day            int
+	Weekly         bool
+	Strike         float64
+	InstrumentType string
+}
+
+// The expiry, strike and type that follow the name of a tradingsymbol. Names
+// may end in digits, e.g. NIFTYNXT50, so the name is not part of the patterns.
+var (
+	// 24OCTFUT of NIFTY24OCTFUT, 24OCT25000CE, 24OCT83.5PE
+	monthlySuffixRegexp = regexp.MustCompile(`^(\d{2})(JAN|FEB|MAR|APR|MAY|JUN|JUL|AUG|SEP|OCT|NOV|DEC)(\d+(?:\.\d+)?)?(CE|PE|FUT)$`)
+	// 24O1725000CE of NIFTY24O1725000CE, 24O1883.5CE, 24O18FUT
+	weeklySuffixRegexp = regexp.MustCompile(`^(\d{2})([1-9OND])(\d{2})(\d+(?:\.\d+)?)?(CE|PE|FUT)$`)
+
+	monthCodes       = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	weeklyMonthCodes = "123456789OND"
+)
+
+// ParseTradingsymbol parses a NFO, BFO, MCX or CDS derivative tradingsymbol.
+// Names may end in digits like NIFTYNXT50, so every split point between the
+// name and a valid expiry, strike and type is tried. When several splits are
+// valid the one with the expiry year nearest to the current year wins, then
+// the one with the shorter name.
+func ParseTradingsymbol(tradingsymbol string) (Tradingsymbol, error) {
+	if tradingsymbol == "" {
+		return Tradingsymbol{}, fmt.Errorf("`tradingsymbol` is required")
+	}
+	symbol := strings.ToUpper(tradingsymbol)
+	year := time.Now().In(istLocation).Year()
+
+	var (
+		best     Tradingsymbol
+		found    bool
+		firstErr error
+	)
+	for i := 1; i < len(symbol); i++ {
+		if symbol[i] < '0' || symbol[i] > '9' {
+			continue
+		}
+		ts, matched, err := parseSuffix(tradingsymbol, symbol[:i], symbol[i:])
+		if !matched {
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !found || yearDistance(ts.Year, year) < yearDistance(best.Year, year) {
+			best, found = ts, true
+		}
+	}
+	if found {
+		return best, nil
+	}
+	if firstErr != nil {
+		return Tradingsymbol{}, firstErr
+	}
+	return Tradingsymbol{}, fmt.Errorf("unrecognised tradingsymbol %q", tradingsymbol)
+}
+
+// yearDistance returns the number of years between a and b - helper function
+func yearDistance(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// parseSuffix parses the expiry, strike and type that follow the name, it
+// reports whether the suffix matched a pattern - helper function
+func parseSuffix(tradingsymbol, name, suffix string) (Tradingsymbol, bool, error) {
+	ts := Tradingsymbol{Name: name}
+
+	if m := monthlySuffixRegexp.FindStringSubmatch(suffix); m != nil {
+		ts.Year = 2000 + atoi(m[1])
+		ts.Month = time.Month(indexOf(monthCodes, m[2]) + 1)
+		if err := ts.setStrikeAndType(tradingsymbol, m[3], m[4]); err != nil {
+			return Tradingsymbol{}, true, err
+		}
+		return ts, true, nil
+	}
+
+	if m := weeklySuffixRegexp.FindStringSubmatch(suffix); m != nil {
+		ts.Year = 2000 + atoi(m[1])
+		ts.Month = time.Month(strings.Index(weeklyMonthCodes, m[2]) + 1)
+		ts.Day = atoi(m[3])
+		ts.Weekly = true
+		if ts.Day < 1 || ts.Day > daysIn(ts.Year, ts.Month) {
+			return Tradingsymbol{}, true, fmt.Errorf("invalid expiry day in tradingsymbol %q", tradingsymbol)
+		}
+		if err := ts.setStrikeAndType(tradingsymbol, m[4], m[5]); err != nil {
+			return Tradingsymbol{}, true, err
+		}
+		return ts, true, nil
+	}
+
+	return Tradingsymbol{}, false, nil
+}
+
+// setStrikeAndType sets the strike and instrument type - helper function
+func (ts *Tradingsymbol) setStrikeAndType(tradingsymbol, strike, instrumentType string) error {
+	ts.InstrumentType = instrumentType
+	if instrumentType == InstrumentTypeFUT {
+		if strike != "" {
+			return fmt.Errorf("unexpected strike in futures tradingsymbol %q", tradingsymbol)
+		}
+		return nil
+	}
+	if strike == "" {
+		return fmt.Errorf("missing strike in options tradingsymbol %q", tradingsymbol)
+	}
+	value, err := strconv.ParseFloat(strike, 64)
+	if err != nil {
+		return fmt.Errorf("invalid strike in tradingsymbol %q: %w", tradingsymbol, err)
+	}
+	if value <= 0 || (len(strike) > 1 && strike[0] == '0' && strike[1] != '.') {
+		return fmt.Errorf("invalid strike in tradingsymbol %q", tradingsymbol)
+	}
+	ts.Strike = value
+	return nil
+}
+
+// String builds the tradingsymbol, returns an empty string if it is invalid
+func (ts Tradingsymbol) String() string {
+	symbol, err := BuildTradingsymbol(ts)
+	if err != nil {
+		return ""
+	}
+	return symbol
+}
+
+// BuildTradingsymbol builds a derivative tradingsymbol from its parts
+func BuildTradingsymbol(ts Tradingsymbol) (string, error) {
+	if ts.Name == "" {
+		return "", fmt.Errorf("`name` is required")
+	}
+	if ts.Year < 2000 || ts.Year > 2099 {
+		return "", fmt.Errorf("invalid `year` %d", ts.Year)
+	}
+	if ts.Month < time.January || ts.Month > time.December {
+		return "", fmt.Errorf("invalid `month` %d", ts.Month)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.ToUpper(ts.Name))
+	sb.WriteString(fmt.Sprintf("%02d", ts.Year%100))
+	if ts.Weekly {
+		if ts.Day < 1 || ts.Day > daysIn(ts.Year, ts.Month) {
+			return "", fmt.Errorf("invalid `day` %d", ts.Day)
+		}
+		sb.WriteByte(weeklyMonthCodes[ts.Month-1])
+		sb.WriteString(fmt.Sprintf("%02d", ts.Day))
+	} else {
+		sb.WriteString(monthCodes[ts.Month-1])
+	}
+
+	switch ts.InstrumentType {
+	case InstrumentTypeFUT:
+		if ts.Strike != 0 {
+			return "", fmt.Errorf("`strike` is not allowed for futures")
+		}
+	case InstrumentTypeCE, InstrumentTypePE:
+		if ts.Strike <= 0 {
+			return "", fmt.Errorf("`strike` is required for options")
+		}
+		sb.WriteString(strconv.FormatFloat(ts.Strike, 'f', -1, 64))
+	default:
+		return "", fmt.Errorf("invalid `instrument_type` %q", ts.InstrumentType)
+	}
+	sb.WriteString(ts.InstrumentType)
+
+	return sb.String(), nil
+}
+
+// Matches reports whether the instrument is the contract described by the tradingsymbol
+func (ts Tradingsymbol) Matches(instrument Instrument) bool {
+	if instrument.Name != ts.Name || instrument.InstrumentType != ts.InstrumentType {
+		return false
+	}
+	if ts.InstrumentType != InstrumentTypeFUT && instrument.Strike != ts.Strike {
+		return false
+	}
+	expiry, err := time.Parse(expiryLayout, instrument.Expiry)
+	if err != nil {
+		return false
+	}
+	if expiry.Year() != ts.Year || expiry.Month() != ts.Month {
+		return false
+	}
+	return !ts.Weekly || expiry.Day() == ts.Day
+}
+
+// ValidateTradingsymbol parses the tradingsymbol and validates it against the
+// instrument returned by `InstrumentsQuery` for the given `exchange`
+func (c *Client) ValidateTradingsymbol(exchange, tradingsymbol string) (Tradingsymbol, Instrument, error) {
+	if exchange == "" {
+		return Tradingsymbol{}, Instrument{}, fmt.Errorf("`exchange` is required")
+	}
+	ts, err := ParseTradingsymbol(tradingsymbol)
+	if err != nil {
+		return Tradingsymbol{}, Instrument{}, err
+	}
+	instruments, err := c.InstrumentsQuery(InstrumentsQueryParams{
+		Exchange:      exchange,
+		Tradingsymbol: strings.ToUpper(tradingsymbol),
+	})
+	if err != nil {
+		return Tradingsymbol{}, Instrument{}, err
+	}
+	for _, instrument := range instruments {
+		if ts.Matches(instrument) {
+			return ts, instrument, nil
+		}
+	}
+	return Tradingsymbol{}, Instrument{}, fmt.Errorf("no %s instrument matches tradingsymbol %q", exchange, tradingsymbol)
+}
+
+// daysIn returns the number of days in the month - helper function
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// atoi converts a string of digits to an int - helper function
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// indexOf returns the index of the value in the slice - helper function
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,122 @@
+package mbconnect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTradingsymbol(t *testing.T) {
+	tests := []struct {
+		exchange      string
+		tradingsymbol string
+		want          Tradingsymbol
+	}{
+		// NFO monthly and weekly
+		{"NFO", "NIFTY24OCTFUT", Tradingsymbol{Name: "NIFTY", Year: 2024, Month: time.October, InstrumentType: InstrumentTypeFUT}},
+		{"NFO", "BANKNIFTY24OCT51500PE", Tradingsymbol{Name: "BANKNIFTY", Year: 2024, Month: time.October, Strike: 51500, InstrumentType: InstrumentTypePE}},
+		{"NFO", "NIFTY24O1725000CE", Tradingsymbol{Name: "NIFTY", Year: 2024, Month: time.October, Day: 17, Weekly: true, Strike: 25000, InstrumentType: InstrumentTypeCE}},
+		{"NFO", "NIFTY24N0724150PE", Tradingsymbol{Name: "NIFTY", Year: 2024, Month: time.November, Day: 7, Weekly: true, Strike: 24150, InstrumentType: InstrumentTypePE}},
+		{"NFO", "NIFTY24D0524500CE", Tradingsymbol{Name: "NIFTY", Year: 2024, Month: time.December, Day: 5, Weekly: true, Strike: 24500, InstrumentType: InstrumentTypeCE}},
+		{"NFO", "NIFTY2511623000CE", Tradingsymbol{Name: "NIFTY", Year: 2025, Month: time.January, Day: 16, Weekly: true, Strike: 23000, InstrumentType: InstrumentTypeCE}},
+		{"NFO", "NIFTY2590424500PE", Tradingsymbol{Name: "NIFTY", Year: 2025, Month: time.September, Day: 4, Weekly: true, Strike: 24500, InstrumentType: InstrumentTypePE}},
+		{"NFO", "FINNIFTY24O2223950.5CE", Tradingsymbol{Name: "FINNIFTY", Year: 2024, Month: time.October, Day: 22, Weekly: true, Strike: 23950.5, InstrumentType: InstrumentTypeCE}},
+		// Names ending in digits
+		{"NFO", "NIFTYNXT5024OCTFUT", Tradingsymbol{Name: "NIFTYNXT50", Year: 2024, Month: time.October, InstrumentType: InstrumentTypeFUT}},
+		{"NFO", "NIFTYNXT5024NOV72000CE", Tradingsymbol{Name: "NIFTYNXT50", Year: 2024, Month: time.November, Strike: 72000, InstrumentType: InstrumentTypeCE}},
+		{"NFO", "NIFTYNXT5024O2571000PE", Tradingsymbol{Name: "NIFTYNXT50", Year: 2024, Month: time.October, Day: 25, Weekly: true, Strike: 71000, InstrumentType: InstrumentTypePE}},
+		{"NFO", "NIFTYNXT502510668000CE", Tradingsymbol{Name: "NIFTYNXT50", Year: 2025, Month: time.January, Day: 6, Weekly: true, Strike: 68000, InstrumentType: InstrumentTypeCE}},
+		// BFO
+		{"BFO", "SENSEX24OCTFUT", Tradingsymbol{Name: "SENSEX", Year: 2024, Month: time.October, InstrumentType: InstrumentTypeFUT}},
+		{"BFO", "SENSEX24O1881000CE", Tradingsymbol{Name: "SENSEX", Year: 2024, Month: time.October, Day: 18, Weekly: true, Strike: 81000, InstrumentType: InstrumentTypeCE}},
+		{"BFO", "BANKEX24N1855000PE", Tradingsymbol{Name: "BANKEX", Year: 2024, Month: time.November, Day: 18, Weekly: true, Strike: 55000, InstrumentType: InstrumentTypePE}},
+		{"BFO", "SENSEX5024OCTFUT", Tradingsymbol{Name: "SENSEX50", Year: 2024, Month: time.October, InstrumentType: InstrumentTypeFUT}},
+		{"BFO", "SENSEX5024NOV26000CE", Tradingsymbol{Name: "SENSEX50", Year: 2024, Month: time.November, Strike: 26000, InstrumentType: InstrumentTypeCE}},
+		{"BFO", "SENSEX5024O2526500PE", Tradingsymbol{Name: "SENSEX50", Year: 2024, Month: time.October, Day: 25, Weekly: true, Strike: 26500, InstrumentType: InstrumentTypePE}},
+		// MCX
+		{"MCX", "CRUDEOIL24NOVFUT", Tradingsymbol{Name: "CRUDEOIL", Year: 2024, Month: time.November, InstrumentType: InstrumentTypeFUT}},
+		{"MCX", "GOLDM24DEC76000CE", Tradingsymbol{Name: "GOLDM", Year: 2024, Month: time.December, Strike: 76000, InstrumentType: InstrumentTypeCE}},
+		{"MCX", "NATURALGAS24OCT230PE", Tradingsymbol{Name: "NATURALGAS", Year: 2024, Month: time.October, Strike: 230, InstrumentType: InstrumentTypePE}},
+		// CDS
+		{"CDS", "USDINR24OCTFUT", Tradingsymbol{Name: "USDINR", Year: 2024, Month: time.October, InstrumentType: InstrumentTypeFUT}},
+		{"CDS", "USDINR24OCT83.5PE", Tradingsymbol{Name: "USDINR", Year: 2024, Month: time.October, Strike: 83.5, InstrumentType: InstrumentTypePE}},
+		{"CDS", "USDINR24O1884.25CE", Tradingsymbol{Name: "USDINR", Year: 2024, Month: time.October, Day: 18, Weekly: true, Strike: 84.25, InstrumentType: InstrumentTypeCE}},
+		{"CDS", "EURINR24NOV90.5CE", Tradingsymbol{Name: "EURINR", Year: 2024, Month: time.November, Strike: 90.5, InstrumentType: InstrumentTypeCE}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exchange+"/"+tt.tradingsymbol, func(t *testing.T) {
+			got, err := ParseTradingsymbol(tt.tradingsymbol)
+			if err != nil {
+				t.Fatalf("ParseTradingsymbol(%q) error: %v", tt.tradingsymbol, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseTradingsymbol(%q) = %+v, want %+v", tt.tradingsymbol, got, tt.want)
+			}
+			built, err := BuildTradingsymbol(got)
+			if err != nil {
+				t.Fatalf("BuildTradingsymbol(%+v) error: %v", got, err)
+			}
+			if built != tt.tradingsymbol {
+				t.Fatalf("BuildTradingsymbol(%+v) = %q, want %q", got, built, tt.tradingsymbol)
+			}
+		})
+	}
+}
+
+func TestParseTradingsymbolErrors(t *testing.T) {
+	for _, tradingsymbol := range []string{
+		"",
+		"SBIN",
+		"NIFTY 50",
+		"NIFTY24OCT25000",
+		"NIFTY24OCTCE",
+		"NIFTY24OCT25000FUT",
+		"NIFTY24O3225000CE",
+		"NIFTY24N3124000PE",
+		"NIFTY24OCT0CE",
+		"NIFTY24OCT025000CE",
+	} {
+		if ts, err := ParseTradingsymbol(tradingsymbol); err == nil {
+			t.Errorf("ParseTradingsymbol(%q) = %+v, want an error", tradingsymbol, ts)
+		}
+	}
+}
+
+func TestBuildTradingsymbolErrors(t *testing.T) {
+	for _, ts := range []Tradingsymbol{
+		{Year: 2024, Month: time.October, InstrumentType: InstrumentTypeFUT},
+		{Name: "NIFTY", Year: 2024, Month: 13, InstrumentType: InstrumentTypeFUT},
+		{Name: "NIFTY", Year: 2024, Month: time.October, InstrumentType: InstrumentTypeCE},
+		{Name: "NIFTY", Year: 2024, Month: time.October, Strike: 25000, InstrumentType: InstrumentTypeFUT},
+		{Name: "NIFTY", Year: 2024, Month: time.February, Day: 30, Weekly: true, Strike: 25000, InstrumentType: InstrumentTypeCE},
+		{Name: "NIFTY", Year: 2024, Month: time.October, Strike: 25000, InstrumentType: "XX"},
+	} {
+		if symbol, err := BuildTradingsymbol(ts); err == nil {
+			t.Errorf("BuildTradingsymbol(%+v) = %q, want an error", ts, symbol)
+		}
+	}
+}
+
+func TestTradingsymbolMatches(t *testing.T) {
+	tests := []struct {
+		tradingsymbol string
+		instrument    Instrument
+		want          bool
+	}{
+		{"NIFTY24O1725000CE", Instrument{Name: "NIFTY", Expiry: "2024-10-17", Strike: 25000, InstrumentType: "CE"}, true},
+		{"NIFTY24O1725000CE", Instrument{Name: "NIFTY", Expiry: "2024-10-24", Strike: 25000, InstrumentType: "CE"}, false},
+		{"NIFTY24OCT25000CE", Instrument{Name: "NIFTY", Expiry: "2024-10-31", Strike: 25000, InstrumentType: "CE"}, true},
+		{"NIFTY24OCT25000CE", Instrument{Name: "NIFTY", Expiry: "2024-10-31", Strike: 25000, InstrumentType: "PE"}, false},
+		{"NIFTY24OCTFUT", Instrument{Name: "NIFTY", Expiry: "2024-10-31", InstrumentType: "FUT"}, true},
+		{"NIFTY24OCTFUT", Instrument{Name: "NIFTY", Expiry: "2024-11-28", InstrumentType: "FUT"}, false},
+		{"USDINR24OCT83.5PE", Instrument{Name: "USDINR", Expiry: "2024-10-29", Strike: 83.5, InstrumentType: "PE"}, true},
+	}
+	for _, tt := range tests {
+		ts, err := ParseTradingsymbol(tt.tradingsymbol)
+		if err != nil {
+			t.Fatalf("ParseTradingsymbol(%q) error: %v", tt.tradingsymbol, err)
+		}
+		if got := ts.Matches(tt.instrument); got != tt.want {
+			t.Errorf("%q.Matches(%+v) = %v, want %v", tt.tradingsymbol, tt.instrument, got, tt.want)
+		}
+	}
+}
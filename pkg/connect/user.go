@@ -0,0 +1,71 @@
+package mbconnect
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// Margin segments
+const (
+	SegmentEquity    = "equity"
+	SegmentCommodity = "commodity"
+)
+
+// AvailableMargins is a struct that represents the funds available in a segment
+type AvailableMargins struct {
+	AdHocMargin    decimal.Decimal `json:"adhoc_margin"`
+	Cash           decimal.Decimal `json:"cash"`
+	Collateral     decimal.Decimal `json:"collateral"`
+	IntradayPayin  decimal.Decimal `json:"intraday_payin"`
+	LiveBalance    decimal.Decimal `json:"live_balance"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+}
+
+// UsedMargins is a struct that represents the funds utilised in a segment
+type UsedMargins struct {
+	Debits           decimal.Decimal `json:"debits"`
+	Exposure         decimal.Decimal `json:"exposure"`
+	M2MRealised      decimal.Decimal `json:"m2m_realised"`
+	M2MUnrealised    decimal.Decimal `json:"m2m_unrealised"`
+	OptionPremium    decimal.Decimal `json:"option_premium"`
+	Payout           decimal.Decimal `json:"payout"`
+	Span             decimal.Decimal `json:"span"`
+	HoldingSales     decimal.Decimal `json:"holding_sales"`
+	Turnover         decimal.Decimal `json:"turnover"`
+	LiquidCollateral decimal.Decimal `json:"liquid_collateral"`
+	StockCollateral  decimal.Decimal `json:"stock_collateral"`
+	Delivery         decimal.Decimal `json:"delivery"`
+}
+
+// Margins is a struct that represents the funds of a segment
+type Margins struct {
+	Enabled   bool             `json:"enabled"`
+	Net       decimal.Decimal  `json:"net"`
+	Available AvailableMargins `json:"available"`
+	Used      UsedMargins      `json:"utilised"`
+}
+
+// AllMargins is a struct that represents the funds of every segment
+type AllMargins struct {
+	Equity    Margins `json:"equity"`
+	Commodity Margins `json:"commodity"`
+}
+
+// GET /user/margins - Get the funds of every segment
+func (c *Client) Margins() (AllMargins, error) {
+	var margins AllMargins
+	err := c.doEnvelope(http.MethodGet, URIUserMargins, nil, nil, &margins)
+	return margins, err
+}
+
+// GET /user/margins/:segment - Get the funds of the `segment`
+func (c *Client) SegmentMargins(segment string) (Margins, error) {
+	var margins Margins
+	if segment != SegmentEquity && segment != SegmentCommodity {
+		return margins, NewError(InputError, fmt.Sprintf("invalid `segment` %q", segment), nil)
+	}
+	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIUserMarginsSegment, segment), nil, nil, &margins)
+	return margins, err
+}
@@ -0,0 +1,166 @@
+package mbexport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is the file format of an export
+type Format string
+
+// Export formats
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// FormatFromPath returns the format of the file by its extension
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".parquet":
+		return FormatParquet, nil
+	default:
+		return "", fmt.Errorf("unsupported file extension %q", filepath.Ext(path))
+	}
+}
+
+// rowCodec converts rows of type T to and from CSV records
+type rowCodec[T any] struct {
+	header     []string
+	toRecord   func(T) []string
+	fromRecord func([]string) (T, error)
+}
+
+// writeRows writes the rows in the format - helper function
+func writeRows[T any](w io.Writer, format Format, rows []T, codec rowCodec[T]) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(codec.header); err != nil {
+			return fmt.Errorf("failed to write csv header: %w", err)
+		}
+		for _, row := range rows {
+			if err := cw.Write(codec.toRecord(row)); err != nil {
+				return fmt.Errorf("failed to write csv record: %w", err)
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case FormatJSONL:
+		bw := bufio.NewWriter(w)
+		enc := json.NewEncoder(bw)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return fmt.Errorf("failed to write json line: %w", err)
+			}
+		}
+		return bw.Flush()
+
+	case FormatParquet:
+		if err := parquet.Write(w, rows); err != nil {
+			return fmt.Errorf("failed to write parquet: %w", err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// readRows reads the rows in the format - helper function
+func readRows[T any](r io.Reader, format Format, codec rowCodec[T]) ([]T, error) {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv header: %w", err)
+		}
+		if strings.Join(header, ",") != strings.Join(codec.header, ",") {
+			return nil, fmt.Errorf("unexpected csv header %v", header)
+		}
+		var rows []T
+		for {
+			record, err := cr.Read()
+			if err == io.EOF {
+				return rows, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read csv record: %w", err)
+			}
+			row, err := codec.fromRecord(record)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse csv record %v: %w", record, err)
+			}
+			rows = append(rows, row)
+		}
+
+	case FormatJSONL:
+		var rows []T
+		dec := json.NewDecoder(r)
+		for {
+			var row T
+			err := dec.Decode(&row)
+			if err == io.EOF {
+				return rows, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read json line: %w", err)
+			}
+			rows = append(rows, row)
+		}
+
+	case FormatParquet:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parquet: %w", err)
+		}
+		rows, err := parquet.Read[T](bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parquet: %w", err)
+		}
+		return rows, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// writeFile creates the file and writes to it - helper function
+func writeFile(path string, write func(io.Writer) error) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to close file: %w", cerr)
+		}
+	}()
+	return write(f)
+}
+
+// readFile opens the file and reads from it - helper function
+func readFile(path string, read func(io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+	return read(bufio.NewReader(f))
+}
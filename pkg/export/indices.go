@@ -0,0 +1,112 @@
+package mbexport
+
+import (
+	"io"
+	"sort"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+)
+
+// indexRow is the flat file representation of an index, `key` is the key of
+// the index in the map returned by `IndicesAll`
+type indexRow struct {
+	Key           string `json:"key" parquet:"key,dict"`
+	Index         string `json:"index" parquet:"index,dict"`
+	Exchange      string `json:"exchange" parquet:"exchange,dict"`
+	Tradingsymbol string `json:"tradingsymbol" parquet:"tradingsymbol"`
+	CompanyName   string `json:"company_name" parquet:"company_name"`
+	Industry      string `json:"industry" parquet:"industry,dict"`
+	Series        string `json:"series" parquet:"series,dict"`
+	ISINCode      string `json:"isin_code" parquet:"isin_code"`
+}
+
+var indexCodec = rowCodec[indexRow]{
+	header: []string{"key", "index", "exchange", "tradingsymbol", "company_name", "industry", "series", "isin_code"},
+	toRecord: func(r indexRow) []string {
+		return []string{r.Key, r.Index, r.Exchange, r.Tradingsymbol, r.CompanyName, r.Industry, r.Series, r.ISINCode}
+	},
+	fromRecord: func(record []string) (indexRow, error) {
+		return indexRow{
+			Key:           record[0],
+			Index:         record[1],
+			Exchange:      record[2],
+			Tradingsymbol: record[3],
+			CompanyName:   record[4],
+			Industry:      record[5],
+			Series:        record[6],
+			ISINCode:      record[7],
+		}, nil
+	},
+}
+
+// WriteIndices writes the indices in the format, sorted by key
+func WriteIndices(w io.Writer, format Format, indices map[string][]mbconnect.Index) error {
+	keys := make([]string, 0, len(indices))
+	for key := range indices {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rows []indexRow
+	for _, key := range keys {
+		for _, index := range indices[key] {
+			rows = append(rows, indexRow{
+				Key:           key,
+				Index:         index.Index,
+				Exchange:      index.Exchange,
+				Tradingsymbol: index.Tradingsymbol,
+				CompanyName:   index.CompanyName,
+				Industry:      index.Industry,
+				Series:        index.Series,
+				ISINCode:      index.ISINCode,
+			})
+		}
+	}
+	return writeRows(w, format, rows, indexCodec)
+}
+
+// ReadIndices reads indices written by `WriteIndices`
+func ReadIndices(r io.Reader, format Format) (map[string][]mbconnect.Index, error) {
+	rows, err := readRows(r, format, indexCodec)
+	if err != nil {
+		return nil, err
+	}
+	indices := make(map[string][]mbconnect.Index)
+	for _, row := range rows {
+		indices[row.Key] = append(indices[row.Key], mbconnect.Index{
+			Index:         row.Index,
+			Exchange:      row.Exchange,
+			Tradingsymbol: row.Tradingsymbol,
+			CompanyName:   row.CompanyName,
+			Industry:      row.Industry,
+			Series:        row.Series,
+			ISINCode:      row.ISINCode,
+		})
+	}
+	return indices, nil
+}
+
+// ExportIndices writes the indices to the file, in the format of its extension
+func ExportIndices(path string, indices map[string][]mbconnect.Index) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, func(w io.Writer) error {
+		return WriteIndices(w, format, indices)
+	})
+}
+
+// ImportIndices reads the indices from the file, in the format of its extension
+func ImportIndices(path string) (map[string][]mbconnect.Index, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var indices map[string][]mbconnect.Index
+	err = readFile(path, func(r io.Reader) error {
+		indices, err = ReadIndices(r, format)
+		return err
+	})
+	return indices, err
+}
@@ -0,0 +1,176 @@
+package mbexport
+
+import (
+	"io"
+	"strconv"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+)
+
+// instrumentRow is the flat file representation of an instrument
+type instrumentRow struct {
+	InstrumentToken uint32  `json:"instrument_token" parquet:"instrument_token"`
+	ExchangeToken   uint32  `json:"exchange_token" parquet:"exchange_token"`
+	Tradingsymbol   string  `json:"tradingsymbol" parquet:"tradingsymbol,dict"`
+	Name            string  `json:"name" parquet:"name,dict"`
+	LastPrice       float64 `json:"last_price" parquet:"last_price"`
+	Expiry          string  `json:"expiry" parquet:"expiry,dict"`
+	Strike          float64 `json:"strike" parquet:"strike"`
+	TickSize        float64 `json:"tick_size" parquet:"tick_size"`
+	LotSize         uint64  `json:"lot_size" parquet:"lot_size"`
+	InstrumentType  string  `json:"instrument_type" parquet:"instrument_type,dict"`
+	Segment         string  `json:"segment" parquet:"segment,dict"`
+	Exchange        string  `json:"exchange" parquet:"exchange,dict"`
+}
+
+var instrumentCodec = rowCodec[instrumentRow]{
+	header: []string{
+		"instrument_token", "exchange_token", "tradingsymbol", "name", "last_price", "expiry",
+		"strike", "tick_size", "lot_size", "instrument_type", "segment", "exchange",
+	},
+	toRecord: func(r instrumentRow) []string {
+		return []string{
+			strconv.FormatUint(uint64(r.InstrumentToken), 10),
+			strconv.FormatUint(uint64(r.ExchangeToken), 10),
+			r.Tradingsymbol,
+			r.Name,
+			strconv.FormatFloat(r.LastPrice, 'f', -1, 64),
+			r.Expiry,
+			strconv.FormatFloat(r.Strike, 'f', -1, 64),
+			strconv.FormatFloat(r.TickSize, 'f', -1, 64),
+			strconv.FormatUint(r.LotSize, 10),
+			r.InstrumentType,
+			r.Segment,
+			r.Exchange,
+		}
+	},
+	fromRecord: func(record []string) (instrumentRow, error) {
+		var (
+			r instrumentRow
+			p = fieldParser{record: record}
+		)
+		r.InstrumentToken = p.uint32(0)
+		r.ExchangeToken = p.uint32(1)
+		r.Tradingsymbol = record[2]
+		r.Name = record[3]
+		r.LastPrice = p.float64(4)
+		r.Expiry = record[5]
+		r.Strike = p.float64(6)
+		r.TickSize = p.float64(7)
+		r.LotSize = p.uint64(8)
+		r.InstrumentType = record[9]
+		r.Segment = record[10]
+		r.Exchange = record[11]
+		return r, p.err
+	},
+}
+
+// WriteInstruments writes the instruments in the format, one row per
+// instrument. Use `WriteOptionChain` for one row per strike.
+func WriteInstruments(w io.Writer, format Format, instruments []mbconnect.Instrument) error {
+	rows := make([]instrumentRow, len(instruments))
+	for i, instrument := range instruments {
+		rows[i] = instrumentRow{
+			InstrumentToken: instrument.InstrumentToken,
+			ExchangeToken:   instrument.ExchangeToken,
+			Tradingsymbol:   instrument.Tradingsymbol,
+			Name:            instrument.Name,
+			LastPrice:       instrument.LastPrice,
+			Expiry:          instrument.Expiry,
+			Strike:          instrument.Strike,
+			TickSize:        instrument.TickSize,
+			LotSize:         uint64(instrument.LotSize),
+			InstrumentType:  instrument.InstrumentType,
+			Segment:         instrument.Segment,
+			Exchange:        instrument.Exchange,
+		}
+	}
+	return writeRows(w, format, rows, instrumentCodec)
+}
+
+// ReadInstruments reads instruments written by `WriteInstruments`
+func ReadInstruments(r io.Reader, format Format) ([]mbconnect.Instrument, error) {
+	rows, err := readRows(r, format, instrumentCodec)
+	if err != nil {
+		return nil, err
+	}
+	instruments := make([]mbconnect.Instrument, len(rows))
+	for i, row := range rows {
+		instruments[i] = mbconnect.Instrument{
+			InstrumentToken: row.InstrumentToken,
+			ExchangeToken:   row.ExchangeToken,
+			Tradingsymbol:   row.Tradingsymbol,
+			Name:            row.Name,
+			LastPrice:       row.LastPrice,
+			Expiry:          row.Expiry,
+			Strike:          row.Strike,
+			TickSize:        row.TickSize,
+			LotSize:         uint(row.LotSize),
+			InstrumentType:  row.InstrumentType,
+			Segment:         row.Segment,
+			Exchange:        row.Exchange,
+		}
+	}
+	return instruments, nil
+}
+
+// ExportInstruments writes the instruments to the file, in the format of its extension
+func ExportInstruments(path string, instruments []mbconnect.Instrument) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, func(w io.Writer) error {
+		return WriteInstruments(w, format, instruments)
+	})
+}
+
+// ImportInstruments reads the instruments from the file, in the format of its extension
+func ImportInstruments(path string) ([]mbconnect.Instrument, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var instruments []mbconnect.Instrument
+	err = readFile(path, func(r io.Reader) error {
+		instruments, err = ReadInstruments(r, format)
+		return err
+	})
+	return instruments, err
+}
+
+// fieldParser parses numeric csv fields, keeping the first error - helper type
+type fieldParser struct {
+	record []string
+	err    error
+}
+
+func (p *fieldParser) uint32(i int) uint32 {
+	return uint32(p.parseUint(i, 32))
+}
+
+func (p *fieldParser) uint64(i int) uint64 {
+	return p.parseUint(i, 64)
+}
+
+func (p *fieldParser) parseUint(i, bitSize int) uint64 {
+	if p.record[i] == "" {
+		return 0
+	}
+	v, err := strconv.ParseUint(p.record[i], 10, bitSize)
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+	return v
+}
+
+func (p *fieldParser) float64(i int) float64 {
+	if p.record[i] == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(p.record[i], 64)
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+	return v
+}
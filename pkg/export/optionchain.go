@@ -0,0 +1,222 @@
+package mbexport
+
+import (
+	"io"
+	"sort"
+	"strconv"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+)
+
+// Option instrument types
+const (
+	instrumentTypeCall = "CE"
+	instrumentTypePut  = "PE"
+)
+
+// optionChainRow is the flat file representation of a strike of an option
+// chain, with the call and put side by side. A side without a listed option
+// has a zero instrument token.
+type optionChainRow struct {
+	Exchange          string  `json:"exchange" parquet:"exchange,dict"`
+	Segment           string  `json:"segment" parquet:"segment,dict"`
+	Name              string  `json:"name" parquet:"name,dict"`
+	Expiry            string  `json:"expiry" parquet:"expiry,dict"`
+	Strike            float64 `json:"strike" parquet:"strike"`
+	TickSize          float64 `json:"tick_size" parquet:"tick_size"`
+	LotSize           uint64  `json:"lot_size" parquet:"lot_size"`
+	CEInstrumentToken uint32  `json:"ce_instrument_token" parquet:"ce_instrument_token"`
+	CEExchangeToken   uint32  `json:"ce_exchange_token" parquet:"ce_exchange_token"`
+	CETradingsymbol   string  `json:"ce_tradingsymbol" parquet:"ce_tradingsymbol"`
+	CELastPrice       float64 `json:"ce_last_price" parquet:"ce_last_price"`
+	PEInstrumentToken uint32  `json:"pe_instrument_token" parquet:"pe_instrument_token"`
+	PEExchangeToken   uint32  `json:"pe_exchange_token" parquet:"pe_exchange_token"`
+	PETradingsymbol   string  `json:"pe_tradingsymbol" parquet:"pe_tradingsymbol"`
+	PELastPrice       float64 `json:"pe_last_price" parquet:"pe_last_price"`
+}
+
+var optionChainCodec = rowCodec[optionChainRow]{
+	header: []string{
+		"exchange", "segment", "name", "expiry", "strike", "tick_size", "lot_size",
+		"ce_instrument_token", "ce_exchange_token", "ce_tradingsymbol", "ce_last_price",
+		"pe_instrument_token", "pe_exchange_token", "pe_tradingsymbol", "pe_last_price",
+	},
+	toRecord: func(r optionChainRow) []string {
+		return []string{
+			r.Exchange,
+			r.Segment,
+			r.Name,
+			r.Expiry,
+			strconv.FormatFloat(r.Strike, 'f', -1, 64),
+			strconv.FormatFloat(r.TickSize, 'f', -1, 64),
+			strconv.FormatUint(r.LotSize, 10),
+			strconv.FormatUint(uint64(r.CEInstrumentToken), 10),
+			strconv.FormatUint(uint64(r.CEExchangeToken), 10),
+			r.CETradingsymbol,
+			strconv.FormatFloat(r.CELastPrice, 'f', -1, 64),
+			strconv.FormatUint(uint64(r.PEInstrumentToken), 10),
+			strconv.FormatUint(uint64(r.PEExchangeToken), 10),
+			r.PETradingsymbol,
+			strconv.FormatFloat(r.PELastPrice, 'f', -1, 64),
+		}
+	},
+	fromRecord: func(record []string) (optionChainRow, error) {
+		var (
+			r optionChainRow
+			p = fieldParser{record: record}
+		)
+		r.Exchange = record[0]
+		r.Segment = record[1]
+		r.Name = record[2]
+		r.Expiry = record[3]
+		r.Strike = p.float64(4)
+		r.TickSize = p.float64(5)
+		r.LotSize = p.uint64(6)
+		r.CEInstrumentToken = p.uint32(7)
+		r.CEExchangeToken = p.uint32(8)
+		r.CETradingsymbol = record[9]
+		r.CELastPrice = p.float64(10)
+		r.PEInstrumentToken = p.uint32(11)
+		r.PEExchangeToken = p.uint32(12)
+		r.PETradingsymbol = record[13]
+		r.PELastPrice = p.float64(14)
+		return r, p.err
+	},
+}
+
+// optionChainKey identifies a strike of an option chain
+type optionChainKey struct {
+	exchange string
+	name     string
+	expiry   string
+	strike   float64
+}
+
+// WriteOptionChain writes the options among the instruments in the format as
+// an option chain, one row per strike and expiry with the call and put side by
+// side, sorted by name, expiry and strike. Instruments that are not options
+// are skipped.
+func WriteOptionChain(w io.Writer, format Format, instruments []mbconnect.Instrument) error {
+	strikes := make(map[optionChainKey]*optionChainRow)
+	var keys []optionChainKey
+	for _, instrument := range instruments {
+		if instrument.InstrumentType != instrumentTypeCall && instrument.InstrumentType != instrumentTypePut {
+			continue
+		}
+		key := optionChainKey{
+			exchange: instrument.Exchange,
+			name:     instrument.Name,
+			expiry:   instrument.Expiry,
+			strike:   instrument.Strike,
+		}
+		row, ok := strikes[key]
+		if !ok {
+			row = &optionChainRow{
+				Exchange: instrument.Exchange,
+				Segment:  instrument.Segment,
+				Name:     instrument.Name,
+				Expiry:   instrument.Expiry,
+				Strike:   instrument.Strike,
+				TickSize: instrument.TickSize,
+				LotSize:  uint64(instrument.LotSize),
+			}
+			strikes[key] = row
+			keys = append(keys, key)
+		}
+		if instrument.InstrumentType == instrumentTypeCall {
+			row.CEInstrumentToken = instrument.InstrumentToken
+			row.CEExchangeToken = instrument.ExchangeToken
+			row.CETradingsymbol = instrument.Tradingsymbol
+			row.CELastPrice = instrument.LastPrice
+		} else {
+			row.PEInstrumentToken = instrument.InstrumentToken
+			row.PEExchangeToken = instrument.ExchangeToken
+			row.PETradingsymbol = instrument.Tradingsymbol
+			row.PELastPrice = instrument.LastPrice
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.exchange != b.exchange {
+			return a.exchange < b.exchange
+		}
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if a.expiry != b.expiry {
+			return a.expiry < b.expiry
+		}
+		return a.strike < b.strike
+	})
+	rows := make([]optionChainRow, len(keys))
+	for i, key := range keys {
+		rows[i] = *strikes[key]
+	}
+	return writeRows(w, format, rows, optionChainCodec)
+}
+
+// ReadOptionChain reads an option chain written by `WriteOptionChain` back
+// into its call and put instruments
+func ReadOptionChain(r io.Reader, format Format) ([]mbconnect.Instrument, error) {
+	rows, err := readRows(r, format, optionChainCodec)
+	if err != nil {
+		return nil, err
+	}
+	var instruments []mbconnect.Instrument
+	for _, row := range rows {
+		option := mbconnect.Instrument{
+			Name:     row.Name,
+			Expiry:   row.Expiry,
+			Strike:   row.Strike,
+			TickSize: row.TickSize,
+			LotSize:  uint(row.LotSize),
+			Segment:  row.Segment,
+			Exchange: row.Exchange,
+		}
+		if row.CEInstrumentToken != 0 {
+			call := option
+			call.InstrumentToken = row.CEInstrumentToken
+			call.ExchangeToken = row.CEExchangeToken
+			call.Tradingsymbol = row.CETradingsymbol
+			call.LastPrice = row.CELastPrice
+			call.InstrumentType = instrumentTypeCall
+			instruments = append(instruments, call)
+		}
+		if row.PEInstrumentToken != 0 {
+			put := option
+			put.InstrumentToken = row.PEInstrumentToken
+			put.ExchangeToken = row.PEExchangeToken
+			put.Tradingsymbol = row.PETradingsymbol
+			put.LastPrice = row.PELastPrice
+			put.InstrumentType = instrumentTypePut
+			instruments = append(instruments, put)
+		}
+	}
+	return instruments, nil
+}
+
+// ExportOptionChain writes the option chain of the instruments to the file, in the format of its extension
+func ExportOptionChain(path string, instruments []mbconnect.Instrument) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, func(w io.Writer) error {
+		return WriteOptionChain(w, format, instruments)
+	})
+}
+
+// ImportOptionChain reads the option chain from the file, in the format of its extension
+func ImportOptionChain(path string) ([]mbconnect.Instrument, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var instruments []mbconnect.Instrument
+	err = readFile(path, func(r io.Reader) error {
+		instruments, err = ReadOptionChain(r, format)
+		return err
+	})
+	return instruments, err
+}
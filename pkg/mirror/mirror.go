@@ -0,0 +1,246 @@
+package mbmirror
+
+import (
+	"fmt"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// batchSize is the number of records upserted per statement
+const batchSize = 1000
+
+// MirrorParams are the parameters for the mirror service
+type MirrorParams struct {
+	SchemaName           string
+	InstrumentsTableName string
+	IndicesTableName     string
+}
+
+// MirrorService is the service for mirroring instruments and indices to a database
+type MirrorService struct {
+	params MirrorParams
+	db     *gorm.DB
+}
+
+// InstrumentRecord is the struct for the instrument record
+type InstrumentRecord struct {
+	InstrumentToken uint32 `gorm:"primarykey;autoIncrement:false"`
+	ExchangeToken   uint32
+	Tradingsymbol   string `gorm:"index"`
+	Name            string `gorm:"index"`
+	LastPrice       float64
+	Expiry          string `gorm:"index"`
+	Strike          float64
+	TickSize        float64
+	LotSize         uint
+	InstrumentType  string
+	Segment         string
+	Exchange        string    `gorm:"index"`
+	UpdatedAt       time.Time `gorm:"index"`
+}
+
+// IndexRecord is the struct for the index record
+type IndexRecord struct {
+	ID            uint   `gorm:"primarykey"`
+	Index         string `gorm:"index:idx_index_exchange_symbol,unique,priority:1"`
+	Exchange      string `gorm:"index:idx_index_exchange_symbol,unique,priority:2"`
+	Tradingsymbol string `gorm:"index:idx_index_exchange_symbol,unique,priority:3;index"`
+	CompanyName   string `gorm:"index"`
+	Industry      string `gorm:"index"`
+	Series        string
+	ISINCode      string
+	UpdatedAt     time.Time `gorm:"index"`
+}
+
+// NewMirrorService creates a new mirror service
+func NewMirrorService(params MirrorParams, db *gorm.DB) (*MirrorService, error) {
+	service := &MirrorService{
+		params: params,
+		db:     db,
+	}
+	return service, service.AutoMigrate()
+}
+
+// AutoMigrate creates or updates the instruments and indices table schemas
+func (s *MirrorService) AutoMigrate() error {
+	if !s.db.Table(s.getInstrumentsTableName()).Migrator().HasTable(&InstrumentRecord{}) {
+		if err := s.db.Table(s.getInstrumentsTableName()).AutoMigrate(&InstrumentRecord{}); err != nil {
+			return err
+		}
+	}
+	if !s.db.Table(s.getIndicesTableName()).Migrator().HasTable(&IndexRecord{}) {
+		if err := s.db.Table(s.getIndicesTableName()).AutoMigrate(&IndexRecord{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getInstrumentsTableName returns the fully qualified instruments table name
+func (s *MirrorService) getInstrumentsTableName() string {
+	return fmt.Sprintf("%s.%s", s.params.SchemaName, s.params.InstrumentsTableName)
+}
+
+// getIndicesTableName returns the fully qualified indices table name
+func (s *MirrorService) getIndicesTableName() string {
+	return fmt.Sprintf("%s.%s", s.params.SchemaName, s.params.IndicesTableName)
+}
+
+// UpsertInstruments upserts the instruments by `instrument_token`
+func (s *MirrorService) UpsertInstruments(instruments []mbconnect.Instrument) error {
+	if len(instruments) == 0 {
+		return nil
+	}
+	now := time.Now()
+	records := make([]InstrumentRecord, len(instruments))
+	for i, instrument := range instruments {
+		records[i] = InstrumentRecord{
+			InstrumentToken: instrument.InstrumentToken,
+			ExchangeToken:   instrument.ExchangeToken,
+			Tradingsymbol:   instrument.Tradingsymbol,
+			Name:            instrument.Name,
+			LastPrice:       instrument.LastPrice,
+			Expiry:          instrument.Expiry,
+			Strike:          instrument.Strike,
+			TickSize:        instrument.TickSize,
+			LotSize:         instrument.LotSize,
+			InstrumentType:  instrument.InstrumentType,
+			Segment:         instrument.Segment,
+			Exchange:        instrument.Exchange,
+			UpdatedAt:       now,
+		}
+	}
+
+	err := s.db.Table(s.getInstrumentsTableName()).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "instrument_token"}},
+		UpdateAll: true,
+	}).CreateInBatches(records, batchSize).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert instruments: %w", err)
+	}
+	return nil
+}
+
+// UpsertIndices upserts the indices by `index`, `exchange` and `tradingsymbol`
+func (s *MirrorService) UpsertIndices(indices []mbconnect.Index) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	now := time.Now()
+	records := make([]IndexRecord, len(indices))
+	for i, index := range indices {
+		records[i] = IndexRecord{
+			Index:         index.Index,
+			Exchange:      index.Exchange,
+			Tradingsymbol: index.Tradingsymbol,
+			CompanyName:   index.CompanyName,
+			Industry:      index.Industry,
+			Series:        index.Series,
+			ISINCode:      index.ISINCode,
+			UpdatedAt:     now,
+		}
+	}
+
+	err := s.db.Table(s.getIndicesTableName()).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "index"}, {Name: "exchange"}, {Name: "tradingsymbol"}},
+		DoUpdates: clause.AssignmentColumns([]string{"company_name", "industry", "series", "isin_code", "updated_at"}),
+	}).CreateInBatches(records, batchSize).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert indices: %w", err)
+	}
+	return nil
+}
+
+// SyncInstruments queries the instruments and upserts them, instruments matching
+// the query that were not returned are pruned when `prune` is set. An empty
+// query result is never pruned against, as it is more likely a failed download
+// than a delisting of every instrument.
+func (s *MirrorService) SyncInstruments(client *mbconnect.Client, qp mbconnect.InstrumentsQueryParams, prune bool) (int, error) {
+	started := time.Now()
+	instruments, err := client.InstrumentsQuery(qp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query instruments: %w", err)
+	}
+	if err := s.UpsertInstruments(instruments); err != nil {
+		return 0, err
+	}
+	if prune {
+		if len(instruments) == 0 {
+			return 0, fmt.Errorf("failed to prune instruments: query returned no instruments")
+		}
+		query := scopeInstruments(s.db.Table(s.getInstrumentsTableName()), qp).Where("updated_at < ?", started)
+		if err := query.Delete(&InstrumentRecord{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to prune instruments: %w", err)
+		}
+	}
+	return len(instruments), nil
+}
+
+// scopeInstruments restricts the query to the instruments matching the query params
+func scopeInstruments(query *gorm.DB, qp mbconnect.InstrumentsQueryParams) *gorm.DB {
+	if qp.Exchange != "" {
+		query = query.Where("exchange = ?", qp.Exchange)
+	}
+	if qp.Tradingsymbol != "" {
+		query = query.Where("tradingsymbol = ?", qp.Tradingsymbol)
+	}
+	if qp.InstrumentToken != 0 {
+		query = query.Where("instrument_token = ?", qp.InstrumentToken)
+	}
+	if qp.Name != "" {
+		query = query.Where("name = ?", qp.Name)
+	}
+	if qp.Expiry != "" {
+		query = query.Where("expiry = ?", qp.Expiry)
+	}
+	if qp.Strike != 0 {
+		query = query.Where("strike = ?", qp.Strike)
+	}
+	if qp.Segment != "" {
+		query = query.Where("segment = ?", qp.Segment)
+	}
+	if qp.InstrumentType != "" {
+		query = query.Where("instrument_type = ?", qp.InstrumentType)
+	}
+	return query
+}
+
+// SyncIndices fetches all indices and upserts them
+func (s *MirrorService) SyncIndices(client *mbconnect.Client) (int, error) {
+	exchangeIndices, err := client.IndicesAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get indices: %w", err)
+	}
+	count := 0
+	for _, indices := range exchangeIndices {
+		if err := s.UpsertIndices(indices); err != nil {
+			return count, err
+		}
+		count += len(indices)
+	}
+	return count, nil
+}
+
+// SyncIndexInstruments fetches the instruments of an index and upserts them
+func (s *MirrorService) SyncIndexInstruments(client *mbconnect.Client, exchange, name string) (int, error) {
+	indices, err := client.IndexInstruments(exchange, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get index instruments: %w", err)
+	}
+	if err := s.UpsertIndices(indices); err != nil {
+		return 0, err
+	}
+	return len(indices), nil
+}
+
+// Close closes the database connection
+func (s *MirrorService) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return sqlDB.Close()
+}
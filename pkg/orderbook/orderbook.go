@@ -0,0 +1,277 @@
+package mborderbook
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+)
+
+// Side is the side of the book an order executes against
+type Side string
+
+// Order sides
+const (
+	SideBuy  Side = "BUY"
+	SideSell Side = "SELL"
+)
+
+// OrderBook is the market depth of an instrument, with 5 or 20 levels per side
+type OrderBook struct {
+	instrument mbconnect.Instrument
+
+	mu        sync.RWMutex
+	bids      []mbconnect.DepthItem
+	asks      []mbconnect.DepthItem
+	lastPrice float64
+	updatedAt time.Time
+}
+
+// SlippageEstimate is the estimated execution of a quantity against the book
+type SlippageEstimate struct {
+	Side          Side
+	Quantity      uint32
+	Filled        uint32
+	AveragePrice  float64
+	WorstPrice    float64
+	Slippage      float64
+	SlippageTicks float64
+}
+
+// New creates a new order book of the instrument, its tick size is used for tick metrics
+func New(instrument mbconnect.Instrument) *OrderBook {
+	return &OrderBook{instrument: instrument}
+}
+
+// Instrument returns the instrument of the book
+func (b *OrderBook) Instrument() mbconnect.Instrument {
+	return b.instrument
+}
+
+// UpdateFromQuote replaces the book with the depth of a quote
+func (b *OrderBook) UpdateFromQuote(quote mbconnect.Quote) {
+	b.update(quote.Depth, quote.LastPrice, quote.Timestamp.Time)
+}
+
+// UpdateFromTick replaces the book with the depth of a full mode tick,
+// ticks without depth only update the last price
+func (b *OrderBook) UpdateFromTick(tick mbticker.Tick) {
+	b.update(tick.Depth, tick.LastPrice, tick.Timestamp)
+}
+
+// UpdateFromDepth replaces the book with the depth
+func (b *OrderBook) UpdateFromDepth(depth mbconnect.Depth, updatedAt time.Time) {
+	b.update(depth, 0, updatedAt)
+}
+
+// update replaces the levels, dropping empty ones
+func (b *OrderBook) update(depth mbconnect.Depth, lastPrice float64, updatedAt time.Time) {
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(depth.Buy) > 0 || len(depth.Sell) > 0 {
+		b.bids = nonEmptyLevels(depth.Buy)
+		b.asks = nonEmptyLevels(depth.Sell)
+	}
+	if lastPrice > 0 {
+		b.lastPrice = lastPrice
+	}
+	b.updatedAt = updatedAt
+}
+
+// Bids returns the buy levels, best first
+func (b *OrderBook) Bids() []mbconnect.DepthItem {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]mbconnect.DepthItem(nil), b.bids...)
+}
+
+// Asks returns the sell levels, best first
+func (b *OrderBook) Asks() []mbconnect.DepthItem {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]mbconnect.DepthItem(nil), b.asks...)
+}
+
+// LastPrice returns the last traded price
+func (b *OrderBook) LastPrice() float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.lastPrice
+}
+
+// UpdatedAt returns the time of the last update
+func (b *OrderBook) UpdatedAt() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.updatedAt
+}
+
+// BestBid returns the best buy level
+func (b *OrderBook) BestBid() (mbconnect.DepthItem, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.bids) == 0 {
+		return mbconnect.DepthItem{}, false
+	}
+	return b.bids[0], true
+}
+
+// BestAsk returns the best sell level
+func (b *OrderBook) BestAsk() (mbconnect.DepthItem, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.asks) == 0 {
+		return mbconnect.DepthItem{}, false
+	}
+	return b.asks[0], true
+}
+
+// Mid returns the mid price between the best bid and ask
+func (b *OrderBook) Mid() (float64, bool) {
+	bid, ask, ok := b.touch()
+	if !ok {
+		return 0, false
+	}
+	return (bid.Price + ask.Price) / 2, true
+}
+
+// Microprice returns the mid price weighted by the quantity on the opposite side
+func (b *OrderBook) Microprice() (float64, bool) {
+	bid, ask, ok := b.touch()
+	if !ok {
+		return 0, false
+	}
+	total := float64(bid.Quantity) + float64(ask.Quantity)
+	if total == 0 {
+		return (bid.Price + ask.Price) / 2, true
+	}
+	return (bid.Price*float64(ask.Quantity) + ask.Price*float64(bid.Quantity)) / total, true
+}
+
+// Spread returns the difference between the best ask and bid
+func (b *OrderBook) Spread() (float64, bool) {
+	bid, ask, ok := b.touch()
+	if !ok {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
+
+// SpreadTicks returns the spread as a number of ticks of the instrument
+func (b *OrderBook) SpreadTicks() (float64, bool) {
+	spread, ok := b.Spread()
+	if !ok || b.instrument.TickSize <= 0 {
+		return 0, false
+	}
+	return math.Round(spread / b.instrument.TickSize), true
+}
+
+// Imbalance returns (bid quantity - ask quantity) / (bid quantity + ask quantity)
+// over the top `levels` of each side, between -1 (all asks) and 1 (all bids).
+// A `levels` of 0 uses every level.
+func (b *OrderBook) Imbalance(levels int) (float64, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	bidQuantity := sumQuantity(b.bids, levels)
+	askQuantity := sumQuantity(b.asks, levels)
+	total := bidQuantity + askQuantity
+	if total == 0 {
+		return 0, false
+	}
+	return (bidQuantity - askQuantity) / total, true
+}
+
+// EstimateSlippage walks the opposite side of the book to estimate the
+// execution of the quantity. Slippage is measured against the touch price and
+// is positive when the execution is worse. If the book is too thin, `Filled`
+// is less than `Quantity`.
+func (b *OrderBook) EstimateSlippage(side Side, quantity uint32) (SlippageEstimate, error) {
+	if quantity == 0 {
+		return SlippageEstimate{}, fmt.Errorf("`quantity` is required")
+	}
+
+	b.mu.RLock()
+	var levels []mbconnect.DepthItem
+	switch side {
+	case SideBuy:
+		levels = b.asks
+	case SideSell:
+		levels = b.bids
+	default:
+		b.mu.RUnlock()
+		return SlippageEstimate{}, fmt.Errorf("invalid `side` %q", side)
+	}
+	levels = append([]mbconnect.DepthItem(nil), levels...)
+	b.mu.RUnlock()
+
+	if len(levels) == 0 {
+		return SlippageEstimate{}, fmt.Errorf("no %s liquidity in the book", side)
+	}
+
+	estimate := SlippageEstimate{Side: side, Quantity: quantity}
+	var notional float64
+	for _, level := range levels {
+		if estimate.Filled >= quantity {
+			break
+		}
+		fill := min(level.Quantity, quantity-estimate.Filled)
+		notional += float64(fill) * level.Price
+		estimate.Filled += fill
+		estimate.WorstPrice = level.Price
+	}
+	if estimate.Filled == 0 {
+		return SlippageEstimate{}, fmt.Errorf("no %s liquidity in the book", side)
+	}
+
+	estimate.AveragePrice = notional / float64(estimate.Filled)
+	touch := levels[0].Price
+	if side == SideBuy {
+		estimate.Slippage = estimate.AveragePrice - touch
+	} else {
+		estimate.Slippage = touch - estimate.AveragePrice
+	}
+	if b.instrument.TickSize > 0 {
+		estimate.SlippageTicks = estimate.Slippage / b.instrument.TickSize
+	}
+	return estimate, nil
+}
+
+// touch returns the best bid and ask
+func (b *OrderBook) touch() (mbconnect.DepthItem, mbconnect.DepthItem, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.bids) == 0 || len(b.asks) == 0 {
+		return mbconnect.DepthItem{}, mbconnect.DepthItem{}, false
+	}
+	return b.bids[0], b.asks[0], true
+}
+
+// nonEmptyLevels returns the levels with a price and quantity - helper function
+func nonEmptyLevels(levels []mbconnect.DepthItem) []mbconnect.DepthItem {
+	out := make([]mbconnect.DepthItem, 0, len(levels))
+	for _, level := range levels {
+		if level.Price > 0 && level.Quantity > 0 {
+			out = append(out, level)
+		}
+	}
+	return out
+}
+
+// sumQuantity sums the quantity of the top levels - helper function
+func sumQuantity(levels []mbconnect.DepthItem, n int) float64 {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	var total float64
+	for _, level := range levels[:n] {
+		total += float64(level.Quantity)
+	}
+	return total
+}
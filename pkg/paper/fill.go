@@ -0,0 +1,191 @@
+package mbpaper
+
+import (
+	"fmt"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	"github.com/shopspring/decimal"
+)
+
+// symbol returns the `EXCHANGE:TRADINGSYMBOL` of the order
+func (o *paperOrder) symbol() string {
+	return o.Order.Exchange + ":" + o.Order.Tradingsymbol
+}
+
+// isBuy reports whether the order buys
+func (o *paperOrder) isBuy() bool {
+	return o.Order.TransactionType == mbconnect.TransactionTypeBuy
+}
+
+// isStop reports whether the order waits for its trigger price
+func (o *paperOrder) isStop() bool {
+	return o.Order.OrderType == mbconnect.OrderTypeSL || o.Order.OrderType == mbconnect.OrderTypeSLM
+}
+
+// limitPrice returns the worst price the order accepts, 0 for market orders
+func (o *paperOrder) limitPrice() float64 {
+	switch o.Order.OrderType {
+	case mbconnect.OrderTypeLimit, mbconnect.OrderTypeSL:
+		return o.Order.Price.InexactFloat64()
+	default:
+		return 0
+	}
+}
+
+// matchSymbol matches the open orders of the symbol against its market, the lock must be held
+func (b *Broker) matchSymbol(symbol string, now time.Time) []mbconnect.Order {
+	m, ok := b.markets[symbol]
+	if !ok || m.lastPrice <= 0 {
+		return nil
+	}
+	// depth consumed by fills stays consumed until the next tick or quote
+	defer func() { b.markets[symbol] = m }()
+
+	var updates []mbconnect.Order
+	for _, o := range b.book.Orders {
+		if o.symbol() != symbol || !o.Order.IsOpen() || now.Before(o.ActiveAt) {
+			continue
+		}
+		if o.isStop() && !o.Triggered {
+			trigger := o.Order.TriggerPrice.InexactFloat64()
+			if (o.isBuy() && m.lastPrice < trigger) || (!o.isBuy() && m.lastPrice > trigger) {
+				continue
+			}
+			o.Triggered = true
+			o.Order.Status = mbconnect.OrderStatusOpen
+			updates = append(updates, b.record(o, now))
+		}
+
+		if filled := b.fill(o, &m, now); filled {
+			updates = append(updates, b.record(o, now))
+		}
+		if o.Order.Validity == mbconnect.ValidityIOC && o.Order.IsOpen() {
+			updates = append(updates, b.cancel(o, now, "IOC order not fully filled"))
+		}
+	}
+	return updates
+}
+
+// fill executes as much of the order as the market allows, the depth it fills
+// against is consumed, the lock must be held
+func (b *Broker) fill(o *paperOrder, m *market, now time.Time) bool {
+	limit := o.limitPrice()
+	var fills []mbconnect.DepthItem
+
+	switch b.params.FillModel {
+	case FillDepth:
+		levels := m.depth.Sell
+		if !o.isBuy() {
+			levels = m.depth.Buy
+		}
+		remaining := o.Order.PendingQuantity
+		for i := range levels {
+			level := &levels[i]
+			if remaining == 0 || level.Price <= 0 || level.Quantity == 0 {
+				continue
+			}
+			if limit > 0 && ((o.isBuy() && level.Price > limit) || (!o.isBuy() && level.Price < limit)) {
+				break
+			}
+			quantity := min(uint(level.Quantity), remaining)
+			fills = append(fills, mbconnect.DepthItem{Price: level.Price, Quantity: uint32(quantity)})
+			level.Quantity -= uint32(quantity)
+			remaining -= quantity
+		}
+	default:
+		if limit > 0 && ((o.isBuy() && m.lastPrice > limit) || (!o.isBuy() && m.lastPrice < limit)) {
+			return false
+		}
+		quantity := o.Order.PendingQuantity
+		if b.params.MaxFillQuantity > 0 {
+			quantity = min(quantity, b.params.MaxFillQuantity)
+		}
+		fills = append(fills, mbconnect.DepthItem{Price: m.lastPrice, Quantity: uint32(quantity)})
+	}
+	if len(fills) == 0 {
+		return false
+	}
+
+	for _, fill := range fills {
+		price := b.slippedPrice(o, fill.Price, limit)
+		quantity := uint(fill.Quantity)
+		filledValue := o.Order.AveragePrice.Mul(decimal.NewFromInt(int64(o.Order.FilledQuantity)))
+		o.Order.FilledQuantity += quantity
+		o.Order.PendingQuantity -= quantity
+		o.Order.AveragePrice = filledValue.Add(price.Mul(decimal.NewFromInt(int64(quantity)))).
+			Div(decimal.NewFromInt(int64(o.Order.FilledQuantity))).Round(4)
+
+		b.book.Trades = append(b.book.Trades, mbconnect.Trade{
+			TradeID:           fmt.Sprintf("%s-%d", o.Order.OrderID, len(b.book.Trades)+1),
+			OrderID:           o.Order.OrderID,
+			Exchange:          o.Order.Exchange,
+			Tradingsymbol:     o.Order.Tradingsymbol,
+			InstrumentToken:   o.Order.InstrumentToken,
+			Product:           o.Order.Product,
+			TransactionType:   o.Order.TransactionType,
+			Quantity:          quantity,
+			AveragePrice:      price,
+			FillTimestamp:     mbconnect.Time{Time: now},
+			OrderTimestamp:    o.Order.OrderTimestamp,
+			ExchangeTimestamp: mbconnect.Time{Time: now},
+		})
+	}
+	if o.Order.PendingQuantity == 0 {
+		o.Order.Status = mbconnect.OrderStatusComplete
+		o.Order.ExchangeTimestamp = mbconnect.Time{Time: now}
+	}
+	return true
+}
+
+// slippedPrice applies the adverse slippage to the price, rounded to the tick
+// size away from the order and capped at its limit price
+func (b *Broker) slippedPrice(o *paperOrder, price, limit float64) decimal.Decimal {
+	instrument := b.instruments[o.symbol()]
+	slippage := price * b.params.SlippageBps / 10000
+	if o.isBuy() {
+		price = instrument.RoundUpToTick(price + slippage)
+		if limit > 0 {
+			price = min(price, limit)
+		}
+	} else {
+		price = instrument.RoundDownToTick(price - slippage)
+		if limit > 0 {
+			price = max(price, limit)
+		}
+	}
+	return decimal.NewFromFloat(price)
+}
+
+// expireSession cancels the open DAY orders placed before the current session,
+// the lock must be held
+func (b *Broker) expireSession(now time.Time) []mbconnect.Order {
+	session := sessionDate(now)
+	if session.Equal(b.session) {
+		return nil
+	}
+	b.session = session
+
+	var updates []mbconnect.Order
+	for _, o := range b.book.Orders {
+		if o.Order.IsOpen() && o.Order.Validity == mbconnect.ValidityDay && sessionDate(o.Order.OrderTimestamp.Time).Before(session) {
+			updates = append(updates, b.cancel(o, now, "DAY order expired at the end of the session"))
+		}
+	}
+	return updates
+}
+
+// sessionDate returns the date of the trading session at the time
+func sessionDate(t time.Time) time.Time {
+	y, m, d := t.In(sessionLocation).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, sessionLocation)
+}
+
+// cancel cancels the pending quantity of the order, the lock must be held
+func (b *Broker) cancel(o *paperOrder, now time.Time, message string) mbconnect.Order {
+	o.Order.Status = mbconnect.OrderStatusCancelled
+	o.Order.StatusMessage = message
+	o.Order.CancelledQuantity = o.Order.PendingQuantity
+	o.Order.PendingQuantity = 0
+	return b.record(o, now)
+}
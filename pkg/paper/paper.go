@@ -0,0 +1,541 @@
+package mbpaper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	mbbroker "github.com/nsvirk/gomoneybotslib/pkg/broker"
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbstate "github.com/nsvirk/gomoneybotslib/pkg/state"
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// defaultStateKey is the state key of the paper book
+const defaultStateKey = "paper_book"
+
+// sessionLocation is the timezone of the trading session, DAY orders expire
+// when its date changes
+var sessionLocation = time.FixedZone("IST", 5*60*60+30*60)
+
+// FillModel is how orders are matched against the market
+type FillModel string
+
+// Fill models
+const (
+	// FillLTP fills at the last traded price
+	FillLTP FillModel = "ltp"
+	// FillDepth walks the opposite side of the market depth, partially
+	// filling orders larger than the quantity on offer
+	FillDepth FillModel = "depth"
+)
+
+// MarketData is the source of quotes for pricing orders, `*mbconnect.Client` satisfies it
+type MarketData interface {
+	Quote(instruments []string) (map[string]mbconnect.Quote, error)
+}
+
+// StateStore persists the paper book, `*mbstate.StateService` satisfies it
+type StateStore interface {
+	Get(key string) (string, map[string]interface{}, error)
+	Set(key, value string, meta map[string]interface{}) error
+}
+
+var _ StateStore = (*mbstate.StateService)(nil)
+
+// PaperParams are the parameters for the paper broker
+type PaperParams struct {
+	// FillModel defaults to `FillLTP`
+	FillModel FillModel
+	// SlippageBps is the adverse slippage applied to every fill, in basis points
+	SlippageBps float64
+	// MaxFillQuantity caps the quantity of each fill with `FillLTP`, so large
+	// orders fill partially over several updates. 0 means no cap.
+	MaxFillQuantity uint
+	// Latency delays orders before they can be matched
+	Latency time.Duration
+	// StateKey is the key of the paper book in the state store, defaults to `paper_book`
+	StateKey string
+}
+
+// Broker is a paper trading broker that simulates fills locally. It has the
+// order and position APIs of `mbconnect.Client`. Prices come from ticks passed
+// to `UpdateTick`, or from `MarketData` quotes when there is no tick.
+type Broker struct {
+	params     PaperParams
+	marketData MarketData
+	state      StateStore
+
+	onOrderUpdate func(mbconnect.Order)
+
+	mu          sync.Mutex
+	book        book
+	instruments map[string]mbconnect.Instrument
+	symbols     map[uint32]string
+	markets     map[string]market
+	session     time.Time
+
+	// saveMu serializes saves, so an older paper book never overwrites a newer one
+	saveMu sync.Mutex
+}
+
+// book is the persisted state of the paper broker
+type book struct {
+	Sequence uint                         `json:"sequence"`
+	Orders   []*paperOrder                `json:"orders"`
+	History  map[string][]mbconnect.Order `json:"history"`
+	Trades   []mbconnect.Trade            `json:"trades"`
+}
+
+// paperOrder is an order and its matching state
+type paperOrder struct {
+	Order     mbconnect.Order `json:"order"`
+	ActiveAt  time.Time       `json:"active_at"`
+	Triggered bool            `json:"triggered"`
+}
+
+// market is the latest market of an instrument
+type market struct {
+	lastPrice float64
+	depth     mbconnect.Depth
+	updatedAt time.Time
+}
+
+var _ mbbroker.Executor = (*Broker)(nil)
+
+// New creates a new paper broker. `marketData` and `state` may be nil, without
+// a state store the paper book is not persisted. An existing paper book in the
+// state store is resumed.
+func New(params PaperParams, marketData MarketData, state StateStore) (*Broker, error) {
+	if params.FillModel == "" {
+		params.FillModel = FillLTP
+	}
+	if params.FillModel != FillLTP && params.FillModel != FillDepth {
+		return nil, fmt.Errorf("invalid `fill_model` %q", params.FillModel)
+	}
+	if params.StateKey == "" {
+		params.StateKey = defaultStateKey
+	}
+
+	b := &Broker{
+		params:      params,
+		marketData:  marketData,
+		state:       state,
+		book:        book{History: make(map[string][]mbconnect.Order)},
+		instruments: make(map[string]mbconnect.Instrument),
+		symbols:     make(map[uint32]string),
+		markets:     make(map[string]market),
+	}
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// OnOrderUpdate sets the callback for order updates.
+func (b *Broker) OnOrderUpdate(fn func(mbconnect.Order)) {
+	b.onOrderUpdate = fn
+}
+
+// SetInstrument registers an instrument, so its ticks are matched against
+// orders and its fill prices are rounded to its tick size
+func (b *Broker) SetInstrument(instrument mbconnect.Instrument) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	symbol := instrument.Exchange + ":" + instrument.Tradingsymbol
+	b.instruments[symbol] = instrument
+	b.symbols[instrument.InstrumentToken] = symbol
+}
+
+// UpdateTick updates the market of a registered instrument and matches its open
+// orders, use it as a `Ticker.OnTick` callback
+func (b *Broker) UpdateTick(tick mbticker.Tick) {
+	b.mu.Lock()
+	symbol, ok := b.symbols[tick.InstrumentToken]
+	if !ok {
+		b.mu.Unlock()
+		return
+	}
+	m := b.markets[symbol]
+	m.lastPrice = tick.LastPrice
+	if len(tick.Depth.Buy) > 0 || len(tick.Depth.Sell) > 0 {
+		m.depth = copyDepth(tick.Depth)
+	}
+	m.updatedAt = time.Now()
+	b.markets[symbol] = m
+	updates := b.expireSession(time.Now())
+	updates = append(updates, b.matchSymbol(symbol, time.Now())...)
+	b.mu.Unlock()
+
+	b.commit(updates)
+}
+
+// Refresh fetches quotes for the instruments with open orders and matches them,
+// call it periodically when prices come from `MarketData`
+func (b *Broker) Refresh() error {
+	b.mu.Lock()
+	updates := b.expireSession(time.Now())
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, o := range b.book.Orders {
+		symbol := o.symbol()
+		if o.Order.IsOpen() && !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	b.mu.Unlock()
+	if len(symbols) == 0 || b.marketData == nil {
+		return b.commit(updates)
+	}
+
+	quotes, err := b.marketData.Quote(symbols)
+	if err != nil {
+		if commitErr := b.commit(updates); commitErr != nil {
+			return commitErr
+		}
+		return fmt.Errorf("failed to refresh quotes: %w", err)
+	}
+
+	b.mu.Lock()
+	for symbol, quote := range quotes {
+		b.setQuote(symbol, quote)
+		updates = append(updates, b.matchSymbol(symbol, time.Now())...)
+	}
+	b.mu.Unlock()
+
+	return b.commit(updates)
+}
+
+// PlaceOrder places a paper order. Orders are validated like the live client
+// and matched immediately unless `Latency` is set.
+func (b *Broker) PlaceOrder(variety string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error) {
+	if err := params.Validate(variety); err != nil {
+		return mbconnect.OrderResponse{}, err
+	}
+	if variety == mbconnect.VarietyCO {
+		return mbconnect.OrderResponse{}, mbconnect.NewError(mbconnect.OrderError, "cover orders are not supported by the paper broker", nil)
+	}
+	symbol := params.Exchange + ":" + params.Tradingsymbol
+	if err := b.ensureMarket(symbol); err != nil {
+		return mbconnect.OrderResponse{}, err
+	}
+
+	now := time.Now()
+	b.mu.Lock()
+	updates := b.expireSession(now)
+	b.book.Sequence++
+	validity := params.Validity
+	if validity == "" {
+		validity = mbconnect.ValidityDay
+	}
+	o := &paperOrder{
+		Order: mbconnect.Order{
+			OrderID:         fmt.Sprintf("PAPER%010d", b.book.Sequence),
+			Status:          mbconnect.OrderStatusOpen,
+			OrderTimestamp:  mbconnect.Time{Time: now},
+			Variety:         variety,
+			Exchange:        params.Exchange,
+			Tradingsymbol:   params.Tradingsymbol,
+			InstrumentToken: b.instruments[symbol].InstrumentToken,
+			OrderType:       params.OrderType,
+			TransactionType: params.TransactionType,
+			Validity:        validity,
+			ValidityTTL:     params.ValidityTTL,
+			Product:         params.Product,
+			Quantity:        params.Quantity,
+			Price:           decimal.NewFromFloat(params.Price),
+			TriggerPrice:    decimal.NewFromFloat(params.TriggerPrice),
+			PendingQuantity: params.Quantity,
+			Tag:             params.Tag,
+		},
+		ActiveAt: now.Add(b.params.Latency),
+	}
+	if params.Tag != "" {
+		o.Order.Tags = []string{params.Tag}
+	}
+	if o.isStop() {
+		o.Order.Status = mbconnect.OrderStatusTriggerPending
+	}
+	b.book.Orders = append(b.book.Orders, o)
+	updates = append(updates, b.record(o, now))
+	updates = append(updates, b.matchSymbol(symbol, now)...)
+	b.mu.Unlock()
+
+	if b.params.Latency > 0 {
+		time.AfterFunc(b.params.Latency, func() {
+			b.mu.Lock()
+			updates := b.matchSymbol(symbol, time.Now())
+			b.mu.Unlock()
+			b.commit(updates)
+		})
+	}
+	return mbconnect.OrderResponse{OrderID: o.Order.OrderID}, b.commit(updates)
+}
+
+// ModifyOrder modifies the quantity, prices, order type or validity of an open paper order
+func (b *Broker) ModifyOrder(variety, orderID string, params mbconnect.OrderParams) (mbconnect.OrderResponse, error) {
+	if err := b.expire(); err != nil {
+		return mbconnect.OrderResponse{}, err
+	}
+	now := time.Now()
+	b.mu.Lock()
+	o, err := b.openOrder(variety, orderID)
+	if err != nil {
+		b.mu.Unlock()
+		return mbconnect.OrderResponse{}, err
+	}
+	if params.Quantity > 0 {
+		if params.Quantity < o.Order.FilledQuantity {
+			b.mu.Unlock()
+			return mbconnect.OrderResponse{}, mbconnect.NewError(mbconnect.InputError, "`quantity` can't be less than the filled quantity", nil)
+		}
+		o.Order.Quantity = params.Quantity
+		o.Order.PendingQuantity = params.Quantity - o.Order.FilledQuantity
+	}
+	if params.OrderType != "" {
+		o.Order.OrderType = params.OrderType
+	}
+	if params.Price > 0 {
+		o.Order.Price = decimal.NewFromFloat(params.Price)
+	}
+	if params.TriggerPrice > 0 {
+		o.Order.TriggerPrice = decimal.NewFromFloat(params.TriggerPrice)
+	}
+	if params.Validity != "" {
+		o.Order.Validity = params.Validity
+	}
+	o.Order.Modified = true
+	updates := []mbconnect.Order{b.record(o, now)}
+	updates = append(updates, b.matchSymbol(o.symbol(), now)...)
+	b.mu.Unlock()
+
+	return mbconnect.OrderResponse{OrderID: orderID}, b.commit(updates)
+}
+
+// CancelOrder cancels the pending quantity of an open paper order
+func (b *Broker) CancelOrder(variety, orderID string) (mbconnect.OrderResponse, error) {
+	if err := b.expire(); err != nil {
+		return mbconnect.OrderResponse{}, err
+	}
+	b.mu.Lock()
+	o, err := b.openOrder(variety, orderID)
+	if err != nil {
+		b.mu.Unlock()
+		return mbconnect.OrderResponse{}, err
+	}
+	update := b.cancel(o, time.Now(), "")
+	b.mu.Unlock()
+
+	return mbconnect.OrderResponse{OrderID: orderID}, b.commit([]mbconnect.Order{update})
+}
+
+// ExitOrder cancels an open paper order, there are no cover orders to exit
+func (b *Broker) ExitOrder(variety, orderID, parentOrderID string) (mbconnect.OrderResponse, error) {
+	return b.CancelOrder(variety, orderID)
+}
+
+// Orders returns the paper orders, DAY orders of a previous session are expired first
+func (b *Broker) Orders() ([]mbconnect.Order, error) {
+	if err := b.expire(); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	orders := make([]mbconnect.Order, len(b.book.Orders))
+	for i, o := range b.book.Orders {
+		orders[i] = o.Order
+	}
+	return orders, nil
+}
+
+// expire expires the DAY orders of a previous session and commits them
+func (b *Broker) expire() error {
+	b.mu.Lock()
+	updates := b.expireSession(time.Now())
+	b.mu.Unlock()
+	return b.commit(updates)
+}
+
+// OrderHistory returns the states of a paper order, oldest first
+func (b *Broker) OrderHistory(orderID string) ([]mbconnect.Order, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	history, ok := b.book.History[orderID]
+	if !ok {
+		return nil, mbconnect.NewError(mbconnect.OrderError, fmt.Sprintf("order %s not found", orderID), nil)
+	}
+	return append([]mbconnect.Order(nil), history...), nil
+}
+
+// Trades returns the paper trades
+func (b *Broker) Trades() ([]mbconnect.Trade, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]mbconnect.Trade(nil), b.book.Trades...), nil
+}
+
+// OrderTrades returns the paper trades of an order
+func (b *Broker) OrderTrades(orderID string) ([]mbconnect.Trade, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var trades []mbconnect.Trade
+	for _, trade := range b.book.Trades {
+		if trade.OrderID == orderID {
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+// Positions returns the net and day paper positions, marked to the latest prices
+func (b *Broker) Positions() (mbconnect.Positions, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	session := sessionDate(time.Now())
+	isToday := func(t mbconnect.Trade) bool {
+		return sessionDate(t.FillTimestamp.Time).Equal(session)
+	}
+	positions := mbconnect.Positions{
+		Net: b.positions(func(mbconnect.Trade) bool { return true }),
+		Day: b.positions(isToday),
+	}
+	setDayTotals(positions.Net, positions.Day)
+	setDayTotals(positions.Day, positions.Day)
+	return positions, nil
+}
+
+// Reset clears the paper book
+func (b *Broker) Reset() error {
+	b.mu.Lock()
+	b.book = book{History: make(map[string][]mbconnect.Order)}
+	b.mu.Unlock()
+	return b.save()
+}
+
+// openOrder returns an open order by id, the lock must be held
+func (b *Broker) openOrder(variety, orderID string) (*paperOrder, error) {
+	for _, o := range b.book.Orders {
+		if o.Order.OrderID != orderID {
+			continue
+		}
+		if variety != "" && o.Order.Variety != variety {
+			return nil, mbconnect.NewError(mbconnect.InputError, fmt.Sprintf("order %s is a %s order", orderID, o.Order.Variety), nil)
+		}
+		if !o.Order.IsOpen() {
+			return nil, mbconnect.NewError(mbconnect.OrderError, fmt.Sprintf("order %s is %s", orderID, o.Order.Status), nil)
+		}
+		return o, nil
+	}
+	return nil, mbconnect.NewError(mbconnect.OrderError, fmt.Sprintf("order %s not found", orderID), nil)
+}
+
+// ensureMarket fetches a quote for the symbol if there is no market yet
+func (b *Broker) ensureMarket(symbol string) error {
+	b.mu.Lock()
+	_, ok := b.markets[symbol]
+	b.mu.Unlock()
+	if ok {
+		return nil
+	}
+	if b.marketData == nil {
+		return mbconnect.NewError(mbconnect.DataError, fmt.Sprintf("no market data for %s", symbol), nil)
+	}
+	quotes, err := b.marketData.Quote([]string{symbol})
+	if err != nil {
+		return err
+	}
+	quote, ok := quotes[symbol]
+	if !ok {
+		return mbconnect.NewError(mbconnect.DataError, fmt.Sprintf("no market data for %s", symbol), nil)
+	}
+	b.mu.Lock()
+	b.setQuote(symbol, quote)
+	b.mu.Unlock()
+	return nil
+}
+
+// setQuote updates the market of the symbol from a quote, the lock must be held
+func (b *Broker) setQuote(symbol string, quote mbconnect.Quote) {
+	b.markets[symbol] = market{lastPrice: quote.LastPrice, depth: copyDepth(quote.Depth), updatedAt: time.Now()}
+	if quote.InstrumentToken != 0 {
+		b.symbols[quote.InstrumentToken] = symbol
+	}
+}
+
+// copyDepth copies the depth, fills consume the broker's copy
+func copyDepth(depth mbconnect.Depth) mbconnect.Depth {
+	return mbconnect.Depth{
+		Buy:  append([]mbconnect.DepthItem(nil), depth.Buy...),
+		Sell: append([]mbconnect.DepthItem(nil), depth.Sell...),
+	}
+}
+
+// record appends the order's state to its history, the lock must be held
+func (b *Broker) record(o *paperOrder, now time.Time) mbconnect.Order {
+	o.Order.ExchangeUpdateTimestamp = mbconnect.Time{Time: now}
+	b.book.History[o.Order.OrderID] = append(b.book.History[o.Order.OrderID], o.Order)
+	return o.Order
+}
+
+// commit persists the paper book if there are updates and invokes the callback for them
+func (b *Broker) commit(updates []mbconnect.Order) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	err := b.save()
+	if b.onOrderUpdate != nil {
+		for _, update := range updates {
+			b.onOrderUpdate(update)
+		}
+	}
+	return err
+}
+
+// load resumes the paper book from the state store
+func (b *Broker) load() error {
+	if b.state == nil {
+		return nil
+	}
+	value, _, err := b.state.Get(b.params.StateKey)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load paper book: %w", err)
+	}
+	if err := json.Unmarshal([]byte(value), &b.book); err != nil {
+		return fmt.Errorf("failed to decode paper book: %w", err)
+	}
+	if b.book.History == nil {
+		b.book.History = make(map[string][]mbconnect.Order)
+	}
+	return nil
+}
+
+// save persists the paper book to the state store. Saves are serialized, so
+// the book encoded by a later save is never older than an earlier one.
+func (b *Broker) save() error {
+	if b.state == nil {
+		return nil
+	}
+	b.saveMu.Lock()
+	defer b.saveMu.Unlock()
+	b.mu.Lock()
+	value, err := json.Marshal(b.book)
+	orders := len(b.book.Orders)
+	trades := len(b.book.Trades)
+	b.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode paper book: %w", err)
+	}
+	meta := map[string]interface{}{"orders": orders, "trades": trades}
+	if err := b.state.Set(b.params.StateKey, string(value), meta); err != nil {
+		return fmt.Errorf("failed to save paper book: %w", err)
+	}
+	return nil
+}
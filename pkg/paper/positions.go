@@ -0,0 +1,99 @@
+package mbpaper
+
+import (
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	"github.com/shopspring/decimal"
+)
+
+// positionKey identifies a position
+type positionKey struct {
+	exchange      string
+	tradingsymbol string
+	product       string
+}
+
+// positions aggregates the trades accepted by the filter into positions, the lock must be held
+func (b *Broker) positions(include func(mbconnect.Trade) bool) []mbconnect.Position {
+	var keys []positionKey
+	positions := make(map[positionKey]*mbconnect.Position)
+	for _, trade := range b.book.Trades {
+		if !include(trade) {
+			continue
+		}
+		key := positionKey{exchange: trade.Exchange, tradingsymbol: trade.Tradingsymbol, product: trade.Product}
+		p, ok := positions[key]
+		if !ok {
+			p = &mbconnect.Position{
+				Exchange:        trade.Exchange,
+				Tradingsymbol:   trade.Tradingsymbol,
+				InstrumentToken: trade.InstrumentToken,
+				Product:         trade.Product,
+				Multiplier:      decimal.NewFromInt(1),
+			}
+			positions[key] = p
+			keys = append(keys, key)
+		}
+		value := trade.AveragePrice.Mul(decimal.NewFromInt(int64(trade.Quantity)))
+		if trade.TransactionType == mbconnect.TransactionTypeBuy {
+			p.BuyQuantity += int(trade.Quantity)
+			p.BuyValue = p.BuyValue.Add(value)
+		} else {
+			p.SellQuantity += int(trade.Quantity)
+			p.SellValue = p.SellValue.Add(value)
+		}
+	}
+
+	out := make([]mbconnect.Position, 0, len(keys))
+	for _, key := range keys {
+		p := positions[key]
+		b.markPosition(p, b.markets[key.exchange+":"+key.tradingsymbol].lastPrice)
+		out = append(out, *p)
+	}
+	return out
+}
+
+// setDayTotals copies the buy and sell totals of the day positions to the matching positions
+func setDayTotals(positions, day []mbconnect.Position) {
+	for i := range positions {
+		p := &positions[i]
+		for _, d := range day {
+			if d.Exchange == p.Exchange && d.Tradingsymbol == p.Tradingsymbol && d.Product == p.Product {
+				p.DayBuyQuantity, p.DayBuyPrice, p.DayBuyValue = d.BuyQuantity, d.BuyPrice, d.BuyValue
+				p.DaySellQuantity, p.DaySellPrice, p.DaySellValue = d.SellQuantity, d.SellPrice, d.SellValue
+			}
+		}
+	}
+}
+
+// markPosition derives the quantity, prices and P&L of the position from its
+// buy and sell totals, marking the open quantity to the last price
+func (b *Broker) markPosition(p *mbconnect.Position, lastPrice float64) {
+	if p.BuyQuantity > 0 {
+		p.BuyPrice = p.BuyValue.Div(decimal.NewFromInt(int64(p.BuyQuantity))).Round(4)
+	}
+	if p.SellQuantity > 0 {
+		p.SellPrice = p.SellValue.Div(decimal.NewFromInt(int64(p.SellQuantity))).Round(4)
+	}
+	p.Quantity = p.BuyQuantity - p.SellQuantity
+	p.Value = p.SellValue.Sub(p.BuyValue)
+	p.LastPrice = decimal.NewFromFloat(lastPrice)
+
+	closed := decimal.NewFromInt(int64(min(p.BuyQuantity, p.SellQuantity)))
+	p.Realised = p.SellPrice.Sub(p.BuyPrice).Mul(closed)
+	open := decimal.NewFromInt(int64(p.Quantity))
+	switch {
+	case p.Quantity > 0:
+		p.AveragePrice = p.BuyPrice
+		p.Unrealised = p.LastPrice.Sub(p.BuyPrice).Mul(open)
+	case p.Quantity < 0:
+		p.AveragePrice = p.SellPrice
+		p.Unrealised = p.LastPrice.Sub(p.SellPrice).Mul(open)
+	default:
+		p.Unrealised = decimal.Zero
+	}
+	if lastPrice <= 0 {
+		p.Unrealised = decimal.Zero
+	}
+	p.PnL = p.Realised.Add(p.Unrealised)
+	p.M2M = p.PnL
+}
@@ -0,0 +1,190 @@
+package mbpostback
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+)
+
+// maxBodySize is the largest postback body accepted
+const maxBodySize = 1 << 20
+
+// ErrInvalidChecksum is returned for postbacks with a missing or wrong checksum
+var ErrInvalidChecksum = errors.New("invalid postback checksum")
+
+// OrderUpdate is a struct that represents an order postback
+type OrderUpdate struct {
+	mbconnect.Order
+	UserID           string `json:"user_id"`
+	AppID            uint64 `json:"app_id"`
+	UnfilledQuantity uint   `json:"unfilled_quantity"`
+	Checksum         string `json:"checksum"`
+}
+
+// Handler is an `http.Handler` that receives order postbacks, verifies their
+// checksum and fans the updates out to the registered callbacks and channels.
+// It responds only after every callback and channel has received the update,
+// so slow consumers delay the response to the broker. A postback whose request
+// is cancelled before the fan out completes is answered with 503.
+type Handler struct {
+	secret string
+
+	mu            sync.Mutex
+	onOrderUpdate func(OrderUpdate)
+	onError       func(error)
+	updateChans   []chan OrderUpdate
+	closed        bool
+	done          chan struct{}
+	inflight      sync.WaitGroup
+}
+
+// New creates a new postback handler that verifies checksums with the secret
+func New(secret string) (*Handler, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("`secret` is required")
+	}
+	return &Handler{secret: secret, done: make(chan struct{})}, nil
+}
+
+// OnOrderUpdate sets the callback for order updates.
+func (h *Handler) OnOrderUpdate(fn func(OrderUpdate)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onOrderUpdate = fn
+}
+
+// OnError sets the callback for rejected postbacks.
+func (h *Handler) OnError(fn func(error)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onError = fn
+}
+
+// Updates returns a channel that receives every order update. It is closed by `Close`.
+func (h *Handler) Updates(buffer int) <-chan OrderUpdate {
+	ch := make(chan OrderUpdate, buffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.updateChans = append(h.updateChans, ch)
+	return ch
+}
+
+// Close closes the update channels once the postbacks being delivered have
+// returned, postbacks received afterwards are rejected.
+func (h *Handler) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	close(h.done)
+	updateChans := h.updateChans
+	h.updateChans = nil
+	h.mu.Unlock()
+
+	h.inflight.Wait()
+	for _, ch := range updateChans {
+		close(ch)
+	}
+}
+
+// ServeHTTP handles a postback request
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		h.reject(w, fmt.Errorf("failed to read postback: %w", err), http.StatusBadRequest)
+		return
+	}
+	update, err := h.Parse(body)
+	if errors.Is(err, ErrInvalidChecksum) {
+		h.reject(w, err, http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		h.reject(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		http.Error(w, "postback receiver closed", http.StatusServiceUnavailable)
+		return
+	}
+	onOrderUpdate := h.onOrderUpdate
+	updateChans := append([]chan OrderUpdate(nil), h.updateChans...)
+	h.inflight.Add(1)
+	h.mu.Unlock()
+	defer h.inflight.Done()
+
+	if onOrderUpdate != nil {
+		onOrderUpdate(update)
+	}
+	for _, ch := range updateChans {
+		select {
+		case ch <- update:
+		case <-r.Context().Done():
+			http.Error(w, "postback cancelled", http.StatusServiceUnavailable)
+			return
+		case <-h.done:
+			http.Error(w, "postback receiver closed", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Parse decodes a postback body and verifies its checksum
+func (h *Handler) Parse(body []byte) (OrderUpdate, error) {
+	// The checksum is over the raw order timestamp, before it is parsed.
+	var raw struct {
+		OrderID        string `json:"order_id"`
+		OrderTimestamp string `json:"order_timestamp"`
+		Checksum       string `json:"checksum"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return OrderUpdate{}, fmt.Errorf("failed to decode postback: %w", err)
+	}
+	expected := Checksum(raw.OrderID, raw.OrderTimestamp, h.secret)
+	if subtle.ConstantTimeCompare([]byte(raw.Checksum), []byte(expected)) != 1 {
+		return OrderUpdate{}, ErrInvalidChecksum
+	}
+
+	var update OrderUpdate
+	if err := json.Unmarshal(body, &update); err != nil {
+		return OrderUpdate{}, fmt.Errorf("failed to decode postback: %w", err)
+	}
+	return update, nil
+}
+
+// Checksum returns the hex SHA-256 of the order id, order timestamp and secret
+func Checksum(orderID, orderTimestamp, secret string) string {
+	sum := sha256.Sum256([]byte(orderID + orderTimestamp + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// reject responds with the status and reports the error
+func (h *Handler) reject(w http.ResponseWriter, err error, status int) {
+	h.mu.Lock()
+	onError := h.onError
+	h.mu.Unlock()
+	if onError != nil {
+		onError(err)
+	}
+	http.Error(w, err.Error(), status)
+}
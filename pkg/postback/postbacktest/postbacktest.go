@@ -0,0 +1,77 @@
+package postbacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mbpostback "github.com/nsvirk/gomoneybotslib/pkg/postback"
+)
+
+// timestampLayout is the layout of the order timestamp in postbacks
+const timestampLayout = "2006-01-02 15:04:05"
+
+// SampleUpdate returns a completed order update for developing and testing receivers
+func SampleUpdate(orderID string) mbpostback.OrderUpdate {
+	now := mbconnect.Time{Time: time.Now().Truncate(time.Second)}
+	return mbpostback.OrderUpdate{
+		Order: mbconnect.Order{
+			PlacedBy:          "SA0123",
+			OrderID:           orderID,
+			ExchangeOrderID:   "1300000001887410",
+			Status:            mbconnect.OrderStatusComplete,
+			OrderTimestamp:    now,
+			ExchangeTimestamp: now,
+			Variety:           mbconnect.VarietyRegular,
+			Exchange:          "NSE",
+			Tradingsymbol:     "SBIN",
+			InstrumentToken:   779521,
+			OrderType:         mbconnect.OrderTypeMarket,
+			TransactionType:   mbconnect.TransactionTypeBuy,
+			Validity:          mbconnect.ValidityDay,
+			Product:           mbconnect.ProductCNC,
+			Quantity:          1,
+			FilledQuantity:    1,
+		},
+		UserID: "SA0123",
+	}
+}
+
+// Payload encodes the update as a postback body signed with the secret
+func Payload(update mbpostback.OrderUpdate, secret string) ([]byte, error) {
+	body, err := json.Marshal(update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode postback: %w", err)
+	}
+
+	// Postbacks carry the order timestamp in the exchange layout, not RFC3339.
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("failed to encode postback: %w", err)
+	}
+	timestamp := ""
+	if !update.OrderTimestamp.IsZero() {
+		timestamp = update.OrderTimestamp.Format(timestampLayout)
+	}
+	fields["order_timestamp"] = timestamp
+	fields["checksum"] = mbpostback.Checksum(update.OrderID, timestamp, secret)
+	return json.Marshal(fields)
+}
+
+// Post sends the update, signed with the secret, to the postback url and
+// returns the response status code
+func Post(url string, update mbpostback.OrderUpdate, secret string) (int, error) {
+	body, err := Payload(update, secret)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to post postback: %w", err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
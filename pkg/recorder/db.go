@@ -0,0 +1,112 @@
+package mbrecorder
+
+import (
+	"fmt"
+	"time"
+
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+	"gorm.io/gorm"
+)
+
+// DBRecorderParams are the parameters for the database recorder
+type DBRecorderParams struct {
+	UserID     string
+	BotID      string
+	SchemaName string
+	TableName  string
+}
+
+// DBRecorder records ticks to a database table
+type DBRecorder struct {
+	params DBRecorderParams
+	db     *gorm.DB
+}
+
+// TickRecord is the struct for the tick record, `Data` is the tick encoded
+// as a binary ticker message so it replays exactly
+type TickRecord struct {
+	ID              uint      `gorm:"primarykey"`
+	UserID          string    `gorm:"index:idx_user_bot_received,priority:1"`
+	BotID           string    `gorm:"index:idx_user_bot_received,priority:2"`
+	ReceivedAt      time.Time `gorm:"index:idx_user_bot_received,priority:3"`
+	InstrumentToken uint32    `gorm:"index"`
+	Mode            string
+	LastPrice       float64
+	Data            []byte `gorm:"type:bytea"`
+}
+
+// NewDBRecorder creates a new database recorder
+func NewDBRecorder(params DBRecorderParams, db *gorm.DB) (*DBRecorder, error) {
+	recorder := &DBRecorder{
+		params: params,
+		db:     db,
+	}
+	return recorder, recorder.autoMigrate()
+}
+
+// autoMigrate auto-migrates the table if it doesn't exist
+func (r *DBRecorder) autoMigrate() error {
+	if r.db.Table(r.getTableName()).Migrator().HasTable(&TickRecord{}) {
+		return nil
+	}
+	return r.db.Table(r.getTableName()).AutoMigrate(&TickRecord{})
+}
+
+// getTableName returns the fully qualified table name
+func (r *DBRecorder) getTableName() string {
+	return fmt.Sprintf("%s.%s", r.params.SchemaName, r.params.TableName)
+}
+
+// Record inserts a batch of ticks received at `receivedAt`
+func (r *DBRecorder) Record(receivedAt time.Time, ticks []mbticker.Tick) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+	records := make([]TickRecord, len(ticks))
+	for i, tick := range ticks {
+		records[i] = TickRecord{
+			UserID:          r.params.UserID,
+			BotID:           r.params.BotID,
+			ReceivedAt:      receivedAt,
+			InstrumentToken: tick.InstrumentToken,
+			Mode:            string(tick.Mode),
+			LastPrice:       tick.LastPrice,
+			Data:            mbticker.MarshalTicks([]mbticker.Tick{tick}),
+		}
+	}
+	if err := r.db.Table(r.getTableName()).Create(&records).Error; err != nil {
+		return fmt.Errorf("failed to insert ticks into database: %w", err)
+	}
+	return nil
+}
+
+// Records retrieves the ticks received between `from` and `to`, oldest first
+func (r *DBRecorder) Records(from, to time.Time) ([]Record, error) {
+	var rows []TickRecord
+	err := r.db.Table(r.getTableName()).
+		Where("user_id = ? AND bot_id = ? AND received_at >= ? AND received_at < ?", r.params.UserID, r.params.BotID, from, to).
+		Order("received_at, id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ticks: %w", err)
+	}
+
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		ticks, err := mbticker.ParseTicks(row.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tick record %d: %w", row.ID, err)
+		}
+		records = append(records, Record{ReceivedAt: row.ReceivedAt, Ticks: ticks})
+	}
+	return records, nil
+}
+
+// Close closes the database connection
+func (r *DBRecorder) Close() error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,254 @@
+package mbrecorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+)
+
+// File format
+//
+// A recording starts with the 5 byte header `MBTK` + version, followed by
+// records of an 8 byte receive time (unix nanoseconds), a 4 byte length and a
+// binary ticker message as encoded by `mbticker.MarshalTicks`. All integers
+// are big endian. Records are only ever appended.
+const (
+	fileMagic   = "MBTK"
+	fileVersion = 1
+	headerSize  = len(fileMagic) + 1
+	recordSize  = 12
+)
+
+// maxRecordSize caps the length of a record's message, no ticker websocket
+// message comes close to it, so a larger length means a corrupt recording
+const maxRecordSize = 1 << 20
+
+// Record is a batch of ticks received together
+type Record struct {
+	ReceivedAt time.Time
+	Ticks      []mbticker.Tick
+}
+
+// Recorder appends ticks to a recording file
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	err  error
+}
+
+// NewRecorder opens the recording file for appending, creating it if needed.
+// A record left incomplete by a crash while recording is truncated, so new
+// records follow the last complete one.
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat recording: %w", err)
+	}
+
+	r := &Recorder{file: file, w: bufio.NewWriter(file)}
+	if info.Size() == 0 {
+		r.w.WriteString(fileMagic)
+		r.w.WriteByte(fileVersion)
+	} else if err := checkHeader(file); err != nil {
+		file.Close()
+		return nil, err
+	} else if err := truncateIncomplete(file, info.Size()); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordTick appends a single tick received now, use it as a `Ticker.OnTick`
+// callback. Errors are sticky and returned by `Flush` and `Close`.
+func (r *Recorder) RecordTick(tick mbticker.Tick) {
+	r.Record(time.Now(), []mbticker.Tick{tick})
+}
+
+// Record appends a batch of ticks received at `receivedAt`. The first error of
+// the recorder is also kept and returned by `Flush` and `Close`.
+func (r *Recorder) Record(receivedAt time.Time, ticks []mbticker.Tick) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+	message := mbticker.MarshalTicks(ticks)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(message) > maxRecordSize {
+		return r.fail(fmt.Errorf("record of %d bytes exceeds the maximum of %d", len(message), maxRecordSize))
+	}
+
+	var header [recordSize]byte
+	binary.BigEndian.PutUint64(header[0:8], uint64(receivedAt.UnixNano()))
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(message)))
+	if _, err := r.w.Write(header[:]); err != nil {
+		return r.fail(fmt.Errorf("failed to write record: %w", err))
+	}
+	if _, err := r.w.Write(message); err != nil {
+		return r.fail(fmt.Errorf("failed to write record: %w", err))
+	}
+	return nil
+}
+
+// Flush writes buffered records to the file and returns the first error of the recorder
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Flush(); err != nil {
+		r.fail(fmt.Errorf("failed to flush recording: %w", err))
+	}
+	return r.err
+}
+
+// Close flushes and closes the recording file and returns the first error of the recorder
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Flush(); err != nil {
+		r.fail(fmt.Errorf("failed to flush recording: %w", err))
+	}
+	if err := r.file.Close(); err != nil {
+		r.fail(fmt.Errorf("failed to close recording: %w", err))
+	}
+	return r.err
+}
+
+// fail keeps the first error of the recorder and returns the error, the lock must be held
+func (r *Recorder) fail(err error) error {
+	if r.err == nil {
+		r.err = err
+	}
+	return err
+}
+
+// Reader reads records from a recording file
+type Reader struct {
+	file *os.File
+	r    *bufio.Reader
+}
+
+// NewReader opens a recording file for reading
+func NewReader(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	if err := checkHeader(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Reader{file: file, r: bufio.NewReader(file)}, nil
+}
+
+// Next returns the next record, or io.EOF at the end of the recording.
+// A record truncated by a crash while recording is treated as the end.
+func (r *Reader) Next() (Record, error) {
+	var header [recordSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Record{}, io.EOF
+		}
+		return Record{}, err
+	}
+	length := binary.BigEndian.Uint32(header[8:12])
+	if length > maxRecordSize {
+		return Record{}, fmt.Errorf("invalid record: length %d exceeds the maximum of %d", length, maxRecordSize)
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(r.r, message); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return Record{}, io.EOF
+		}
+		return Record{}, err
+	}
+
+	ticks, err := mbticker.ParseTicks(message)
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid record: %w", err)
+	}
+	return Record{
+		ReceivedAt: time.Unix(0, int64(binary.BigEndian.Uint64(header[0:8]))),
+		Ticks:      ticks,
+	}, nil
+}
+
+// Close closes the recording file
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// ReadFile reads every record of a recording file
+func ReadFile(path string) ([]Record, error) {
+	reader, err := NewReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var records []Record
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// truncateIncomplete truncates the file after its last complete record - helper function
+func truncateIncomplete(file *os.File, size int64) error {
+	offset := int64(headerSize)
+	var header [recordSize]byte
+	for offset+recordSize <= size {
+		if _, err := file.ReadAt(header[:], offset); err != nil {
+			return fmt.Errorf("failed to scan recording: %w", err)
+		}
+		length := binary.BigEndian.Uint32(header[8:12])
+		if length > maxRecordSize || offset+recordSize+int64(length) > size {
+			break
+		}
+		offset += recordSize + int64(length)
+	}
+	if offset == size {
+		return nil
+	}
+	if err := file.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to truncate incomplete record: %w", err)
+	}
+	return nil
+}
+
+// checkHeader validates the header at the start of the file - helper function
+func checkHeader(file *os.File) error {
+	var header [headerSize]byte
+	if _, err := file.ReadAt(header[:], 0); err != nil {
+		return fmt.Errorf("failed to read recording header: %w", err)
+	}
+	if string(header[:len(fileMagic)]) != fileMagic {
+		return fmt.Errorf("not a tick recording")
+	}
+	if header[len(fileMagic)] != fileVersion {
+		return fmt.Errorf("unsupported recording version %d", header[len(fileMagic)])
+	}
+	if _, err := file.Seek(int64(headerSize), io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek recording: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package mbrecorder
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+)
+
+// Replay speeds
+const (
+	// SpeedMax replays as fast as possible
+	SpeedMax float64 = 0
+	// SpeedRealtime replays with the recorded gaps between ticks
+	SpeedRealtime float64 = 1
+)
+
+// Replayer re-emits recorded ticks through the same `mbticker.Source`
+// interface as the live ticker. Ticks are emitted in recorded order, so a
+// replay is deterministic regardless of speed.
+type Replayer struct {
+	records []Record
+	path    string
+	speed   float64
+
+	onTick    func(mbticker.Tick)
+	onError   func(error)
+	tickChans []chan mbticker.Tick
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+var _ mbticker.Source = (*Replayer)(nil)
+
+// NewReplayer creates a new replayer of the records. A speed of 1 replays in
+// real time, 10 replays 10x faster and `SpeedMax` replays without waiting.
+func NewReplayer(records []Record, speed float64) *Replayer {
+	if speed < 0 {
+		speed = SpeedMax
+	}
+	return &Replayer{records: records, speed: speed}
+}
+
+// NewFileReplayer creates a new replayer of a recording file. The file is
+// streamed record by record during the replay, not loaded into memory.
+func NewFileReplayer(path string, speed float64) (*Replayer, error) {
+	reader, err := NewReader(path)
+	if err != nil {
+		return nil, err
+	}
+	reader.Close()
+
+	r := NewReplayer(nil, speed)
+	r.path = path
+	return r, nil
+}
+
+// OnTick sets the callback for ticks.
+func (r *Replayer) OnTick(fn func(mbticker.Tick)) {
+	r.onTick = fn
+}
+
+// OnError sets the callback for errors reading the recording file.
+func (r *Replayer) OnError(fn func(error)) {
+	r.onError = fn
+}
+
+// Ticks returns a channel that receives every tick. It must be called before
+// `Serve` and is closed when the replay ends.
+func (r *Replayer) Ticks(buffer int) <-chan mbticker.Tick {
+	ch := make(chan mbticker.Tick, buffer)
+	r.tickChans = append(r.tickChans, ch)
+	return ch
+}
+
+// Serve replays every record and returns at the end of the recording or on `Stop`.
+func (r *Replayer) Serve() {
+	r.ServeWithContext(context.Background())
+}
+
+// ServeWithContext replays every record and returns at the end of the
+// recording, when the context is cancelled or on `Stop`.
+func (r *Replayer) ServeWithContext(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	r.mu.Lock()
+	r.cancel = cancel
+	r.mu.Unlock()
+	defer cancel()
+	defer r.closeTickChans()
+
+	next, closeRecords, err := r.openRecords()
+	if err != nil {
+		r.triggerError(err)
+		return
+	}
+	defer closeRecords()
+
+	var (
+		timer    *time.Timer
+		previous time.Time
+	)
+	for i := 0; ; i++ {
+		record, err := next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			r.triggerError(err)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if i > 0 && r.speed > 0 {
+			gap := time.Duration(float64(record.ReceivedAt.Sub(previous)) / r.speed)
+			if gap > 0 {
+				if timer == nil {
+					timer = time.NewTimer(gap)
+				} else {
+					timer.Reset(gap)
+				}
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}
+		previous = record.ReceivedAt
+
+		for _, tick := range record.Ticks {
+			if r.onTick != nil {
+				r.onTick(tick)
+			}
+			for _, ch := range r.tickChans {
+				select {
+				case ch <- tick:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// Stop stops the replay.
+func (r *Replayer) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// openRecords returns an iterator over the records to replay, reading the
+// recording file when the replayer was created from one
+func (r *Replayer) openRecords() (func() (Record, error), func(), error) {
+	if r.path == "" {
+		i := 0
+		next := func() (Record, error) {
+			if i >= len(r.records) {
+				return Record{}, io.EOF
+			}
+			i++
+			return r.records[i-1], nil
+		}
+		return next, func() {}, nil
+	}
+	reader, err := NewReader(r.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader.Next, func() { reader.Close() }, nil
+}
+
+func (r *Replayer) triggerError(err error) {
+	if r.onError != nil {
+		r.onError(err)
+	}
+}
+
+func (r *Replayer) closeTickChans() {
+	for _, ch := range r.tickChans {
+		close(ch)
+	}
+	r.tickChans = nil
+}
@@ -0,0 +1,377 @@
+package mbsnapshot
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+	mblogger "github.com/nsvirk/gomoneybotslib/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// dateLayout is the layout of snapshot dates
+const dateLayout = "2006-01-02"
+
+// scopeAll is the scope of a snapshot of every instrument
+const scopeAll = "all"
+
+// SnapshotParams are the parameters for the snapshot service
+type SnapshotParams struct {
+	SchemaName string
+	TableName  string
+}
+
+// SnapshotService is the service for storing daily instrument snapshots
+type SnapshotService struct {
+	params SnapshotParams
+	db     *gorm.DB
+}
+
+// SnapshotRecord is the struct for an instrument in a daily snapshot. The
+// scope identifies the query the snapshot was captured with, so snapshots of
+// different exchanges or segments on the same day are kept apart.
+type SnapshotRecord struct {
+	ID              uint      `gorm:"primarykey"`
+	Date            time.Time `gorm:"type:date;index:idx_date_scope_exchange_symbol,unique,priority:1"`
+	Scope           string    `gorm:"index:idx_date_scope_exchange_symbol,unique,priority:2"`
+	Exchange        string    `gorm:"index:idx_date_scope_exchange_symbol,unique,priority:3"`
+	Tradingsymbol   string    `gorm:"index:idx_date_scope_exchange_symbol,unique,priority:4"`
+	InstrumentToken uint32
+	ExchangeToken   uint32
+	Name            string `gorm:"index"`
+	Expiry          string
+	Strike          float64
+	TickSize        float64
+	LotSize         uint
+	InstrumentType  string
+	Segment         string
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+// FieldChange is a change of a field of an instrument between two snapshots
+type FieldChange struct {
+	Exchange      string
+	Tradingsymbol string
+	Old           float64
+	New           float64
+}
+
+// DiffReport is the struct for the differences between two snapshots
+type DiffReport struct {
+	Scope           string
+	From            time.Time
+	To              time.Time
+	Added           []mbconnect.Instrument
+	Removed         []mbconnect.Instrument
+	LotSizeChanges  []FieldChange
+	TickSizeChanges []FieldChange
+}
+
+// NewSnapshotService creates a new snapshot service
+func NewSnapshotService(params SnapshotParams, db *gorm.DB) (*SnapshotService, error) {
+	service := &SnapshotService{
+		params: params,
+		db:     db,
+	}
+	return service, service.AutoMigrate()
+}
+
+// AutoMigrate creates or updates the snapshot table schema
+func (s *SnapshotService) AutoMigrate() error {
+	if s.db.Table(s.getTableName()).Migrator().HasTable(&SnapshotRecord{}) {
+		return nil
+	}
+	return s.db.Table(s.getTableName()).AutoMigrate(&SnapshotRecord{})
+}
+
+// Scope returns the snapshot scope of the query params, e.g. `exchange=NFO`
+func Scope(qp mbconnect.InstrumentsQueryParams) string {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	set("exchange", qp.Exchange)
+	set("tradingsymbol", qp.Tradingsymbol)
+	if qp.InstrumentToken != 0 {
+		values.Set("instrument_token", strconv.FormatUint(uint64(qp.InstrumentToken), 10))
+	}
+	set("name", qp.Name)
+	set("expiry", qp.Expiry)
+	if qp.Strike != 0 {
+		values.Set("strike", strconv.FormatFloat(qp.Strike, 'f', -1, 64))
+	}
+	set("segment", qp.Segment)
+	set("instrument_type", qp.InstrumentType)
+	if len(values) == 0 {
+		return scopeAll
+	}
+	return values.Encode()
+}
+
+// getTableName returns the fully qualified table name
+func (s *SnapshotService) getTableName() string {
+	return fmt.Sprintf("%s.%s", s.params.SchemaName, s.params.TableName)
+}
+
+// Save replaces the snapshot of the scope for the date with the given instruments
+func (s *SnapshotService) Save(scope string, date time.Time, instruments []mbconnect.Instrument) error {
+	if scope == "" {
+		return fmt.Errorf("`scope` is required")
+	}
+	day := truncateDate(date)
+	records := make([]SnapshotRecord, len(instruments))
+	for i, instrument := range instruments {
+		records[i] = newSnapshotRecord(scope, day, instrument)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(s.getTableName()).Where("date = ? AND scope = ?", day, scope).Delete(&SnapshotRecord{}).Error; err != nil {
+			return fmt.Errorf("failed to delete snapshot: %w", err)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		if err := tx.Table(s.getTableName()).CreateInBatches(records, 1000).Error; err != nil {
+			return fmt.Errorf("failed to insert snapshot: %w", err)
+		}
+		return nil
+	})
+}
+
+// Load retrieves the instruments of the snapshot of the scope for the date
+func (s *SnapshotService) Load(scope string, date time.Time) ([]mbconnect.Instrument, error) {
+	var records []SnapshotRecord
+	err := s.db.Table(s.getTableName()).
+		Where("date = ? AND scope = ?", truncateDate(date), scope).
+		Order("exchange, tradingsymbol").
+		Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve snapshot: %w", err)
+	}
+
+	instruments := make([]mbconnect.Instrument, len(records))
+	for i, record := range records {
+		instruments[i] = record.instrument()
+	}
+	return instruments, nil
+}
+
+// PreviousDate returns the latest snapshot date of the scope before the given date
+func (s *SnapshotService) PreviousDate(scope string, date time.Time) (time.Time, bool, error) {
+	var dates []time.Time
+	err := s.db.Table(s.getTableName()).
+		Distinct("date").
+		Where("date < ? AND scope = ?", truncateDate(date), scope).
+		Order("date desc").
+		Limit(1).
+		Pluck("date", &dates).Error
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to retrieve previous snapshot date: %w", err)
+	}
+	if len(dates) == 0 {
+		return time.Time{}, false, nil
+	}
+	return truncateDate(dates[0]), true, nil
+}
+
+// Diff compares the snapshots of the scope for the two dates
+func (s *SnapshotService) Diff(scope string, from, to time.Time) (*DiffReport, error) {
+	oldInstruments, err := s.Load(scope, from)
+	if err != nil {
+		return nil, err
+	}
+	newInstruments, err := s.Load(scope, to)
+	if err != nil {
+		return nil, err
+	}
+	report := DiffInstruments(oldInstruments, newInstruments)
+	report.Scope = scope
+	report.From = truncateDate(from)
+	report.To = truncateDate(to)
+	return report, nil
+}
+
+// Capture queries the instruments, saves them as the snapshot of the query's
+// scope for the date and returns the diff against the previous snapshot of the
+// same scope, or nil if there is none
+func (s *SnapshotService) Capture(client *mbconnect.Client, qp mbconnect.InstrumentsQueryParams, date time.Time) (*DiffReport, error) {
+	instruments, err := client.InstrumentsQuery(qp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query instruments: %w", err)
+	}
+	// An empty result is more likely a failed download than a delisting of
+	// every instrument, saving it would report every instrument as removed.
+	if len(instruments) == 0 {
+		return nil, fmt.Errorf("failed to capture snapshot: query returned no instruments")
+	}
+	scope := Scope(qp)
+	if err := s.Save(scope, date, instruments); err != nil {
+		return nil, err
+	}
+
+	previous, ok, err := s.PreviousDate(scope, date)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return s.Diff(scope, previous, date)
+}
+
+// Close closes the database connection
+func (s *SnapshotService) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// DiffInstruments compares two sets of instruments keyed by exchange and tradingsymbol
+func DiffInstruments(oldInstruments, newInstruments []mbconnect.Instrument) *DiffReport {
+	report := &DiffReport{}
+
+	oldMap := make(map[string]mbconnect.Instrument, len(oldInstruments))
+	for _, instrument := range oldInstruments {
+		oldMap[instrumentKey(instrument)] = instrument
+	}
+	newMap := make(map[string]mbconnect.Instrument, len(newInstruments))
+	for _, instrument := range newInstruments {
+		newMap[instrumentKey(instrument)] = instrument
+	}
+
+	for key, newInstrument := range newMap {
+		oldInstrument, ok := oldMap[key]
+		if !ok {
+			report.Added = append(report.Added, newInstrument)
+			continue
+		}
+		if oldInstrument.LotSize != newInstrument.LotSize {
+			report.LotSizeChanges = append(report.LotSizeChanges, FieldChange{
+				Exchange:      newInstrument.Exchange,
+				Tradingsymbol: newInstrument.Tradingsymbol,
+				Old:           float64(oldInstrument.LotSize),
+				New:           float64(newInstrument.LotSize),
+			})
+		}
+		if oldInstrument.TickSize != newInstrument.TickSize {
+			report.TickSizeChanges = append(report.TickSizeChanges, FieldChange{
+				Exchange:      newInstrument.Exchange,
+				Tradingsymbol: newInstrument.Tradingsymbol,
+				Old:           oldInstrument.TickSize,
+				New:           newInstrument.TickSize,
+			})
+		}
+	}
+	for key, oldInstrument := range oldMap {
+		if _, ok := newMap[key]; !ok {
+			report.Removed = append(report.Removed, oldInstrument)
+		}
+	}
+
+	sortInstruments(report.Added)
+	sortInstruments(report.Removed)
+	sortChanges(report.LotSizeChanges)
+	sortChanges(report.TickSizeChanges)
+	return report
+}
+
+// IsEmpty reports whether the two snapshots are identical
+func (r *DiffReport) IsEmpty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 &&
+		len(r.LotSizeChanges) == 0 && len(r.TickSizeChanges) == 0
+}
+
+// Log logs the report through the logger service, changes to lot and tick
+// sizes are logged as warnings as they affect order sizing
+func (r *DiffReport) Log(logger *mblogger.LoggerService) {
+	logger.Info("Instrument snapshot diff", map[string]interface{}{
+		"scope":             r.Scope,
+		"from":              r.From.Format(dateLayout),
+		"to":                r.To.Format(dateLayout),
+		"added":             len(r.Added),
+		"removed":           len(r.Removed),
+		"lot_size_changes":  len(r.LotSizeChanges),
+		"tick_size_changes": len(r.TickSizeChanges),
+	})
+	for _, change := range r.LotSizeChanges {
+		logger.Warning("Lot size changed", change.meta())
+	}
+	for _, change := range r.TickSizeChanges {
+		logger.Warning("Tick size changed", change.meta())
+	}
+}
+
+// meta returns the change as log metadata
+func (c FieldChange) meta() map[string]interface{} {
+	return map[string]interface{}{
+		"exchange":      c.Exchange,
+		"tradingsymbol": c.Tradingsymbol,
+		"old":           c.Old,
+		"new":           c.New,
+	}
+}
+
+// newSnapshotRecord creates a snapshot record from an instrument
+func newSnapshotRecord(scope string, date time.Time, instrument mbconnect.Instrument) SnapshotRecord {
+	return SnapshotRecord{
+		Date:            date,
+		Scope:           scope,
+		Exchange:        instrument.Exchange,
+		Tradingsymbol:   instrument.Tradingsymbol,
+		InstrumentToken: instrument.InstrumentToken,
+		ExchangeToken:   instrument.ExchangeToken,
+		Name:            instrument.Name,
+		Expiry:          instrument.Expiry,
+		Strike:          instrument.Strike,
+		TickSize:        instrument.TickSize,
+		LotSize:         instrument.LotSize,
+		InstrumentType:  instrument.InstrumentType,
+		Segment:         instrument.Segment,
+	}
+}
+
+// instrument converts the snapshot record back to an instrument
+func (r SnapshotRecord) instrument() mbconnect.Instrument {
+	return mbconnect.Instrument{
+		InstrumentToken: r.InstrumentToken,
+		ExchangeToken:   r.ExchangeToken,
+		Tradingsymbol:   r.Tradingsymbol,
+		Name:            r.Name,
+		Expiry:          r.Expiry,
+		Strike:          r.Strike,
+		TickSize:        r.TickSize,
+		LotSize:         r.LotSize,
+		InstrumentType:  r.InstrumentType,
+		Segment:         r.Segment,
+		Exchange:        r.Exchange,
+	}
+}
+
+// instrumentKey returns the key of an instrument - helper function
+func instrumentKey(instrument mbconnect.Instrument) string {
+	return instrument.Exchange + ":" + instrument.Tradingsymbol
+}
+
+// truncateDate truncates the time to its date - helper function
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func sortInstruments(instruments []mbconnect.Instrument) {
+	sort.Slice(instruments, func(i, j int) bool {
+		return instrumentKey(instruments[i]) < instrumentKey(instruments[j])
+	})
+}
+
+func sortChanges(changes []FieldChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Exchange != changes[j].Exchange {
+			return changes[i].Exchange < changes[j].Exchange
+		}
+		return changes[i].Tradingsymbol < changes[j].Tradingsymbol
+	})
+}
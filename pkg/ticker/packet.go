@@ -0,0 +1,310 @@
+package mbticker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+)
+
+// Mode is the streaming mode of a subscribed instrument
+type Mode string
+
+// Streaming modes
+const (
+	ModeLTP   Mode = "ltp"
+	ModeQuote Mode = "quote"
+	ModeFull  Mode = "full"
+)
+
+// Exchange segments encoded in the last byte of the instrument token
+const (
+	segmentNSE     = 1
+	segmentNFO     = 2
+	segmentCDS     = 3
+	segmentBSE     = 4
+	segmentBFO     = 5
+	segmentBCD     = 6
+	segmentMCX     = 7
+	segmentMCXSX   = 8
+	segmentIndices = 9
+)
+
+// Packet lengths by mode
+const (
+	packetLengthLTP        = 8
+	packetLengthIndexQuote = 28
+	packetLengthIndexFull  = 32
+	packetLengthQuote      = 44
+	packetLengthFull       = 184
+)
+
+// depthLevels is the number of depth levels on each side of a full packet
+const depthLevels = 5
+
+// Tick is a struct that represents a decoded market data packet
+type Tick struct {
+	Mode            Mode
+	InstrumentToken uint32
+	IsTradable      bool
+	IsIndex         bool
+
+	Timestamp     time.Time
+	LastTradeTime time.Time
+
+	LastPrice          float64
+	LastTradedQuantity uint32
+	TotalBuyQuantity   uint32
+	TotalSellQuantity  uint32
+	VolumeTraded       uint32
+	AverageTradePrice  float64
+	OI                 uint32
+	OIDayHigh          uint32
+	OIDayLow           uint32
+	NetChange          float64
+
+	OHLC  mbconnect.OHLC
+	Depth mbconnect.Depth
+}
+
+// ParseTicks decodes a binary message into ticks, heartbeats decode to no ticks
+func ParseTicks(b []byte) ([]Tick, error) {
+	if len(b) < 2 {
+		return nil, nil
+	}
+	count := int(binary.BigEndian.Uint16(b[0:2]))
+	ticks := make([]Tick, 0, count)
+	offset := 2
+	for i := 0; i < count; i++ {
+		if offset+2 > len(b) {
+			return nil, fmt.Errorf("truncated message: packet %d header", i)
+		}
+		size := int(binary.BigEndian.Uint16(b[offset : offset+2]))
+		offset += 2
+		if offset+size > len(b) {
+			return nil, fmt.Errorf("truncated message: packet %d of %d bytes", i, size)
+		}
+		tick, err := parsePacket(b[offset : offset+size])
+		if err != nil {
+			return nil, err
+		}
+		ticks = append(ticks, tick)
+		offset += size
+	}
+	return ticks, nil
+}
+
+// parsePacket decodes a single packet
+func parsePacket(b []byte) (Tick, error) {
+	if len(b) < 4 {
+		return Tick{}, fmt.Errorf("invalid packet of %d bytes", len(b))
+	}
+	token := binary.BigEndian.Uint32(b[0:4])
+	segment := token & 0xFF
+	divisor := priceDivisor(segment)
+	isIndex := segment == segmentIndices
+	price := func(offset int) float64 {
+		return float64(binary.BigEndian.Uint32(b[offset:offset+4])) / divisor
+	}
+	u32 := func(offset int) uint32 {
+		return binary.BigEndian.Uint32(b[offset : offset+4])
+	}
+
+	tick := Tick{
+		InstrumentToken: token,
+		IsIndex:         isIndex,
+		IsTradable:      !isIndex,
+	}
+
+	switch len(b) {
+	case packetLengthLTP:
+		tick.Mode = ModeLTP
+		tick.LastPrice = price(4)
+
+	case packetLengthIndexQuote, packetLengthIndexFull:
+		tick.Mode = ModeQuote
+		tick.LastPrice = price(4)
+		tick.OHLC = mbconnect.OHLC{
+			High:  price(8),
+			Low:   price(12),
+			Open:  price(16),
+			Close: price(20),
+		}
+		tick.NetChange = float64(int32(u32(24))) / divisor
+		if len(b) == packetLengthIndexFull {
+			tick.Mode = ModeFull
+			tick.Timestamp = unixTime(u32(28))
+		}
+
+	case packetLengthQuote, packetLengthFull:
+		tick.Mode = ModeQuote
+		tick.LastPrice = price(4)
+		tick.LastTradedQuantity = u32(8)
+		tick.AverageTradePrice = price(12)
+		tick.VolumeTraded = u32(16)
+		tick.TotalBuyQuantity = u32(20)
+		tick.TotalSellQuantity = u32(24)
+		tick.OHLC = mbconnect.OHLC{
+			Open:  price(28),
+			High:  price(32),
+			Low:   price(36),
+			Close: price(40),
+		}
+		tick.NetChange = tick.LastPrice - tick.OHLC.Close
+		if len(b) == packetLengthFull {
+			tick.Mode = ModeFull
+			tick.LastTradeTime = unixTime(u32(44))
+			tick.OI = u32(48)
+			tick.OIDayHigh = u32(52)
+			tick.OIDayLow = u32(56)
+			tick.Timestamp = unixTime(u32(60))
+			tick.Depth.Buy = make([]mbconnect.DepthItem, depthLevels)
+			tick.Depth.Sell = make([]mbconnect.DepthItem, depthLevels)
+			for i := 0; i < 2*depthLevels; i++ {
+				offset := 64 + i*12
+				item := mbconnect.DepthItem{
+					Quantity: u32(offset),
+					Price:    price(offset + 4),
+					Orders:   uint32(binary.BigEndian.Uint16(b[offset+8 : offset+10])),
+				}
+				if i < depthLevels {
+					tick.Depth.Buy[i] = item
+				} else {
+					tick.Depth.Sell[i-depthLevels] = item
+				}
+			}
+		}
+
+	default:
+		return Tick{}, fmt.Errorf("unknown packet of %d bytes for token %d", len(b), token)
+	}
+
+	return tick, nil
+}
+
+// MarshalTicks encodes ticks into a binary message, the inverse of `ParseTicks`
+func MarshalTicks(ticks []Tick) []byte {
+	packets := make([][]byte, len(ticks))
+	size := 2
+	for i, tick := range ticks {
+		packets[i] = marshalPacket(tick)
+		size += 2 + len(packets[i])
+	}
+
+	b := make([]byte, 0, size)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(packets)))
+	for _, packet := range packets {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(packet)))
+		b = append(b, packet...)
+	}
+	return b
+}
+
+// marshalPacket encodes a single packet by the tick's mode
+func marshalPacket(tick Tick) []byte {
+	segment := tick.InstrumentToken & 0xFF
+	divisor := priceDivisor(segment)
+	isIndex := segment == segmentIndices
+
+	var size int
+	switch {
+	case tick.Mode == ModeLTP:
+		size = packetLengthLTP
+	case isIndex && tick.Mode == ModeQuote:
+		size = packetLengthIndexQuote
+	case isIndex:
+		size = packetLengthIndexFull
+	case tick.Mode == ModeQuote:
+		size = packetLengthQuote
+	default:
+		size = packetLengthFull
+	}
+
+	b := make([]byte, size)
+	putU32 := func(offset int, v uint32) {
+		binary.BigEndian.PutUint32(b[offset:offset+4], v)
+	}
+	putPrice := func(offset int, v float64) {
+		putU32(offset, uint32(math.Round(v*divisor)))
+	}
+
+	putU32(0, tick.InstrumentToken)
+	putPrice(4, tick.LastPrice)
+	if size == packetLengthLTP {
+		return b
+	}
+
+	if isIndex {
+		putPrice(8, tick.OHLC.High)
+		putPrice(12, tick.OHLC.Low)
+		putPrice(16, tick.OHLC.Open)
+		putPrice(20, tick.OHLC.Close)
+		putU32(24, uint32(int32(math.Round(tick.NetChange*divisor))))
+		if size == packetLengthIndexFull {
+			putU32(28, unixSeconds(tick.Timestamp))
+		}
+		return b
+	}
+
+	putU32(8, tick.LastTradedQuantity)
+	putPrice(12, tick.AverageTradePrice)
+	putU32(16, tick.VolumeTraded)
+	putU32(20, tick.TotalBuyQuantity)
+	putU32(24, tick.TotalSellQuantity)
+	putPrice(28, tick.OHLC.Open)
+	putPrice(32, tick.OHLC.High)
+	putPrice(36, tick.OHLC.Low)
+	putPrice(40, tick.OHLC.Close)
+	if size == packetLengthQuote {
+		return b
+	}
+
+	putU32(44, unixSeconds(tick.LastTradeTime))
+	putU32(48, tick.OI)
+	putU32(52, tick.OIDayHigh)
+	putU32(56, tick.OIDayLow)
+	putU32(60, unixSeconds(tick.Timestamp))
+	for i := 0; i < 2*depthLevels; i++ {
+		var item mbconnect.DepthItem
+		if i < depthLevels && i < len(tick.Depth.Buy) {
+			item = tick.Depth.Buy[i]
+		} else if i >= depthLevels && i-depthLevels < len(tick.Depth.Sell) {
+			item = tick.Depth.Sell[i-depthLevels]
+		}
+		offset := 64 + i*12
+		putU32(offset, item.Quantity)
+		putPrice(offset+4, item.Price)
+		binary.BigEndian.PutUint16(b[offset+8:offset+10], uint16(item.Orders))
+	}
+	return b
+}
+
+// priceDivisor returns the divisor of prices for the segment - helper function
+func priceDivisor(segment uint32) float64 {
+	switch segment {
+	case segmentCDS:
+		return 10000000.0
+	case segmentBCD:
+		return 10000.0
+	default:
+		return 100.0
+	}
+}
+
+// unixTime converts a unix timestamp to time, 0 is the zero time - helper function
+func unixTime(sec uint32) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(sec), 0)
+}
+
+// unixSeconds converts a time to a unix timestamp, the zero time is 0 - helper function
+func unixSeconds(t time.Time) uint32 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint32(t.Unix())
+}
@@ -0,0 +1,145 @@
+package mbticker
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+)
+
+func TestMarshalParseTicks(t *testing.T) {
+	const (
+		tokenNSE   = 779521 // segment 1
+		tokenIndex = 256265 // segment 9
+		tokenCDS   = 1234<<8 | segmentCDS
+	)
+	ts := time.Unix(1730265600, 0)
+	depth := mbconnect.Depth{
+		Buy:  make([]mbconnect.DepthItem, depthLevels),
+		Sell: make([]mbconnect.DepthItem, depthLevels),
+	}
+	for i := 0; i < depthLevels; i++ {
+		depth.Buy[i] = mbconnect.DepthItem{Price: float64(81230-5*i) / 100, Quantity: uint32(100 * (i + 1)), Orders: uint32(i + 1)}
+		depth.Sell[i] = mbconnect.DepthItem{Price: float64(81240+5*i) / 100, Quantity: uint32(200 * (i + 1)), Orders: uint32(i + 2)}
+	}
+
+	tests := []struct {
+		name string
+		size int
+		tick Tick
+	}{
+		{
+			name: "ltp",
+			size: packetLengthLTP,
+			tick: Tick{Mode: ModeLTP, InstrumentToken: tokenNSE, IsTradable: true, LastPrice: 812.35},
+		},
+		{
+			name: "ltp cds",
+			size: packetLengthLTP,
+			tick: Tick{Mode: ModeLTP, InstrumentToken: tokenCDS, IsTradable: true, LastPrice: 83.1225},
+		},
+		{
+			name: "index quote",
+			size: packetLengthIndexQuote,
+			tick: Tick{
+				Mode: ModeQuote, InstrumentToken: tokenIndex, IsIndex: true, LastPrice: 24205.35,
+				OHLC:      mbconnect.OHLC{Open: 24100, High: 24250.5, Low: 24050.25, Close: 24300},
+				NetChange: -94.65,
+			},
+		},
+		{
+			name: "index full",
+			size: packetLengthIndexFull,
+			tick: Tick{
+				Mode: ModeFull, InstrumentToken: tokenIndex, IsIndex: true, LastPrice: 24205.35,
+				OHLC:      mbconnect.OHLC{Open: 24100, High: 24250.5, Low: 24050.25, Close: 24300},
+				NetChange: -94.65,
+				Timestamp: ts,
+			},
+		},
+		{
+			name: "quote",
+			size: packetLengthQuote,
+			tick: Tick{
+				Mode: ModeQuote, InstrumentToken: tokenNSE, IsTradable: true, LastPrice: 812.35,
+				LastTradedQuantity: 10, AverageTradePrice: 810.5, VolumeTraded: 1500000,
+				TotalBuyQuantity: 300000, TotalSellQuantity: 250000,
+				OHLC:      mbconnect.OHLC{Open: 805, High: 815, Low: 800.1, Close: 802.35},
+				NetChange: 10,
+			},
+		},
+		{
+			name: "full",
+			size: packetLengthFull,
+			tick: Tick{
+				Mode: ModeFull, InstrumentToken: tokenNSE, IsTradable: true, LastPrice: 812.35,
+				LastTradedQuantity: 10, AverageTradePrice: 810.5, VolumeTraded: 1500000,
+				TotalBuyQuantity: 300000, TotalSellQuantity: 250000,
+				OHLC:          mbconnect.OHLC{Open: 805, High: 815, Low: 800.1, Close: 802.35},
+				NetChange:     10,
+				LastTradeTime: ts.Add(-time.Second),
+				OI:            120000, OIDayHigh: 130000, OIDayLow: 110000,
+				Timestamp: ts,
+				Depth:     depth,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := MarshalTicks([]Tick{tt.tick})
+			if got := int(binary.BigEndian.Uint16(message[2:4])); got != tt.size {
+				t.Fatalf("packet size = %d, want %d", got, tt.size)
+			}
+			ticks, err := ParseTicks(message)
+			if err != nil {
+				t.Fatalf("ParseTicks() error = %v", err)
+			}
+			if len(ticks) != 1 {
+				t.Fatalf("got %d ticks, want 1", len(ticks))
+			}
+			if !reflect.DeepEqual(ticks[0], tt.tick) {
+				t.Errorf("round trip\n got %+v\nwant %+v", ticks[0], tt.tick)
+			}
+		})
+	}
+}
+
+func TestParseTicksMultiplePackets(t *testing.T) {
+	in := []Tick{
+		{Mode: ModeLTP, InstrumentToken: 779521, IsTradable: true, LastPrice: 812.35},
+		{Mode: ModeLTP, InstrumentToken: 256265, IsIndex: true, LastPrice: 24205.35},
+	}
+	ticks, err := ParseTicks(MarshalTicks(in))
+	if err != nil {
+		t.Fatalf("ParseTicks() error = %v", err)
+	}
+	if !reflect.DeepEqual(ticks, in) {
+		t.Errorf("got %+v, want %+v", ticks, in)
+	}
+}
+
+func TestParseTicksErrors(t *testing.T) {
+	message := MarshalTicks([]Tick{{Mode: ModeLTP, InstrumentToken: 779521, LastPrice: 1}})
+	tests := []struct {
+		name    string
+		message []byte
+		ticks   int
+		wantErr bool
+	}{
+		{"heartbeat", []byte{0}, 0, false},
+		{"truncated header", message[:3], 0, true},
+		{"truncated packet", message[:len(message)-1], 0, true},
+		{"unknown packet size", []byte{0, 1, 0, 6, 0, 0, 0, 1, 0, 0}, 0, true},
+	}
+	for _, tt := range tests {
+		ticks, err := ParseTicks(tt.message)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ParseTicks() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if len(ticks) != tt.ticks {
+			t.Errorf("%s: got %d ticks, want %d", tt.name, len(ticks), tt.ticks)
+		}
+	}
+}
@@ -0,0 +1,534 @@
+package mbticker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	mbconnect "github.com/nsvirk/gomoneybotslib/pkg/connect"
+)
+
+const (
+	tickerURL                  string        = "wss://ws.moneybots.app"
+	defaultConnectTimeout      time.Duration = 7000 * time.Millisecond
+	defaultReconnectMaxDelay   time.Duration = 60000 * time.Millisecond
+	defaultReconnectMaxRetries int           = 300
+	reconnectMinDelay          time.Duration = 5000 * time.Millisecond
+	dataTimeoutInterval        time.Duration = 5000 * time.Millisecond
+)
+
+// Message actions sent to the server
+const (
+	actionSubscribe   = "subscribe"
+	actionUnsubscribe = "unsubscribe"
+	actionMode        = "mode"
+)
+
+// Text message types sent by the server
+const (
+	messageError   = "error"
+	messageMessage = "message"
+	messageOrder   = "order"
+)
+
+// Source is a source of ticks, implemented by the live `Ticker` and by
+// replayers of recorded sessions
+type Source interface {
+	OnTick(fn func(Tick))
+	Ticks(buffer int) <-chan Tick
+	Serve()
+	ServeWithContext(ctx context.Context)
+	Stop()
+}
+
+// Ticker is a WebSocket client for streaming market data
+type Ticker struct {
+	userID   string
+	enctoken string
+	url      url.URL
+
+	autoReconnect       bool
+	reconnectMaxRetries int
+	reconnectMaxDelay   time.Duration
+	connectTimeout      time.Duration
+
+	callbacks callbacks
+	tickChans []chan Tick
+
+	mu            sync.Mutex
+	conn          *websocket.Conn
+	subscriptions map[uint32]Mode
+	cancel        context.CancelFunc
+	stopped       bool
+}
+
+// callbacks is the set of registered event callbacks
+type callbacks struct {
+	onTick        func(Tick)
+	onMessage     func(messageType int, message []byte)
+	onOrderUpdate func(json.RawMessage)
+	onError       func(error)
+	onConnect     func()
+	onClose       func(code int, reason string)
+	onReconnect   func(attempt int, delay time.Duration)
+	onNoReconnect func(attempt int)
+}
+
+// textMessage is the envelope of text messages sent by the server
+type textMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// tickerInput is a message sent to the server
+type tickerInput struct {
+	Action string      `json:"a"`
+	Value  interface{} `json:"v"`
+}
+
+var _ Source = (*Ticker)(nil)
+
+// New creates a new ticker that authenticates with the client's user id and enctoken
+func New(client *mbconnect.Client) *Ticker {
+	return NewWithCredentials(client.UserID(), client.Enctoken())
+}
+
+// NewWithCredentials creates a new ticker with a user id and enctoken
+func NewWithCredentials(userID, enctoken string) *Ticker {
+	u, _ := url.Parse(tickerURL)
+	return &Ticker{
+		userID:              userID,
+		enctoken:            enctoken,
+		url:                 *u,
+		autoReconnect:       true,
+		reconnectMaxRetries: defaultReconnectMaxRetries,
+		reconnectMaxDelay:   defaultReconnectMaxDelay,
+		connectTimeout:      defaultConnectTimeout,
+		subscriptions:       make(map[uint32]Mode),
+	}
+}
+
+// SetRootURL overrides the default WebSocket endpoint, e.g. to connect to a local test server.
+func (t *Ticker) SetRootURL(u url.URL) {
+	t.url = u
+}
+
+// SetEnctoken sets the enctoken used on the next connection.
+func (t *Ticker) SetEnctoken(enctoken string) {
+	t.enctoken = enctoken
+}
+
+// SetAutoReconnect enables or disables auto reconnection.
+func (t *Ticker) SetAutoReconnect(autoReconnect bool) {
+	t.autoReconnect = autoReconnect
+}
+
+// SetReconnectMaxRetries sets the maximum number of reconnection attempts.
+func (t *Ticker) SetReconnectMaxRetries(retries int) {
+	t.reconnectMaxRetries = retries
+}
+
+// SetReconnectMaxDelay sets the maximum delay between reconnection attempts.
+func (t *Ticker) SetReconnectMaxDelay(delay time.Duration) error {
+	if delay < reconnectMinDelay {
+		return fmt.Errorf("`delay` must be at least %v", reconnectMinDelay)
+	}
+	t.reconnectMaxDelay = delay
+	return nil
+}
+
+// SetConnectTimeout sets the timeout of the WebSocket handshake.
+func (t *Ticker) SetConnectTimeout(timeout time.Duration) {
+	t.connectTimeout = timeout
+}
+
+// OnTick sets the callback for ticks.
+func (t *Ticker) OnTick(fn func(Tick)) {
+	t.callbacks.onTick = fn
+}
+
+// OnMessage sets the callback for every raw message.
+func (t *Ticker) OnMessage(fn func(messageType int, message []byte)) {
+	t.callbacks.onMessage = fn
+}
+
+// OnOrderUpdate sets the callback for order updates sent over the socket.
+func (t *Ticker) OnOrderUpdate(fn func(json.RawMessage)) {
+	t.callbacks.onOrderUpdate = fn
+}
+
+// OnError sets the callback for errors.
+func (t *Ticker) OnError(fn func(error)) {
+	t.callbacks.onError = fn
+}
+
+// OnConnect sets the callback for successful connections, including reconnections.
+func (t *Ticker) OnConnect(fn func()) {
+	t.callbacks.onConnect = fn
+}
+
+// OnClose sets the callback for closed connections.
+func (t *Ticker) OnClose(fn func(code int, reason string)) {
+	t.callbacks.onClose = fn
+}
+
+// OnReconnect sets the callback for reconnection attempts.
+func (t *Ticker) OnReconnect(fn func(attempt int, delay time.Duration)) {
+	t.callbacks.onReconnect = fn
+}
+
+// OnNoReconnect sets the callback for when the maximum reconnection attempts are exhausted.
+func (t *Ticker) OnNoReconnect(fn func(attempt int)) {
+	t.callbacks.onNoReconnect = fn
+}
+
+// Ticks returns a channel that receives every tick. It must be called before
+// `Serve` and the channel must be drained, as delivery blocks the reader until
+// the tick is received or the ticker is stopped. The channel is closed when
+// `Serve` returns.
+func (t *Ticker) Ticks(buffer int) <-chan Tick {
+	ch := make(chan Tick, buffer)
+	t.tickChans = append(t.tickChans, ch)
+	return ch
+}
+
+// Serve connects and reads messages until `Stop` is called or reconnection gives up.
+func (t *Ticker) Serve() {
+	t.ServeWithContext(context.Background())
+}
+
+// ServeWithContext connects and reads messages until the context is cancelled,
+// `Stop` is called or reconnection gives up. It returns immediately if `Stop`
+// was called before it.
+func (t *Ticker) ServeWithContext(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer t.closeTickChans()
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	t.cancel = cancel
+	t.mu.Unlock()
+
+	attempt := 0
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if attempt > 0 {
+			if !t.autoReconnect {
+				return
+			}
+			if attempt > t.reconnectMaxRetries {
+				t.triggerNoReconnect(attempt)
+				return
+			}
+			delay := t.reconnectDelay(attempt)
+			t.triggerReconnect(attempt, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		if err := t.connect(ctx); err != nil {
+			t.triggerError(fmt.Errorf("connection failed: %w", err))
+			attempt++
+			continue
+		}
+		attempt = 0
+
+		t.triggerConnect()
+		if err := t.resubscribe(); err != nil {
+			t.triggerError(err)
+		}
+
+		t.readMessages(ctx)
+		attempt++
+	}
+}
+
+// Stop closes the connection and stops serving, a ticker can't be served again once stopped.
+func (t *Ticker) Stop() {
+	t.mu.Lock()
+	t.stopped = true
+	cancel := t.cancel
+	conn := t.conn
+	t.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if conn != nil {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		conn.Close()
+	}
+}
+
+// Subscribe subscribes the tokens in quote mode, or their current mode if already subscribed.
+func (t *Ticker) Subscribe(tokens []uint32) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	for _, token := range tokens {
+		if _, ok := t.subscriptions[token]; !ok {
+			t.subscriptions[token] = ModeQuote
+		}
+	}
+	t.mu.Unlock()
+	return t.send(tickerInput{Action: actionSubscribe, Value: tokens})
+}
+
+// Unsubscribe unsubscribes the tokens.
+func (t *Ticker) Unsubscribe(tokens []uint32) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	for _, token := range tokens {
+		delete(t.subscriptions, token)
+	}
+	t.mu.Unlock()
+	return t.send(tickerInput{Action: actionUnsubscribe, Value: tokens})
+}
+
+// SetMode sets the streaming mode of subscribed tokens.
+func (t *Ticker) SetMode(mode Mode, tokens []uint32) error {
+	if mode != ModeLTP && mode != ModeQuote && mode != ModeFull {
+		return fmt.Errorf("invalid `mode` %q", mode)
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	for _, token := range tokens {
+		t.subscriptions[token] = mode
+	}
+	t.mu.Unlock()
+	return t.send(tickerInput{Action: actionMode, Value: []interface{}{mode, tokens}})
+}
+
+// Subscriptions returns the subscribed tokens and their modes.
+func (t *Ticker) Subscriptions() map[uint32]Mode {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	subscriptions := make(map[uint32]Mode, len(t.subscriptions))
+	for token, mode := range t.subscriptions {
+		subscriptions[token] = mode
+	}
+	return subscriptions
+}
+
+// connect dials the WebSocket endpoint
+func (t *Ticker) connect(ctx context.Context) error {
+	u := t.url
+	q := u.Query()
+	q.Set("user_id", t.userID)
+	q.Set("enctoken", t.enctoken)
+	u.RawQuery = q.Encode()
+
+	dialer := websocket.Dialer{HandshakeTimeout: t.connectTimeout}
+	conn, _, err := dialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.conn = conn
+	t.mu.Unlock()
+	return nil
+}
+
+// resubscribe restores the subscriptions and modes on a new connection
+func (t *Ticker) resubscribe() error {
+	t.mu.Lock()
+	modes := make(map[Mode][]uint32)
+	tokens := make([]uint32, 0, len(t.subscriptions))
+	for token, mode := range t.subscriptions {
+		tokens = append(tokens, token)
+		modes[mode] = append(modes[mode], token)
+	}
+	t.mu.Unlock()
+
+	if len(tokens) == 0 {
+		return nil
+	}
+	if err := t.send(tickerInput{Action: actionSubscribe, Value: tokens}); err != nil {
+		return fmt.Errorf("failed to resubscribe: %w", err)
+	}
+	for mode, modeTokens := range modes {
+		if err := t.send(tickerInput{Action: actionMode, Value: []interface{}{mode, modeTokens}}); err != nil {
+			return fmt.Errorf("failed to restore mode %s: %w", mode, err)
+		}
+	}
+	return nil
+}
+
+// send writes a message if connected, subscriptions made while disconnected
+// are sent on the next connection
+func (t *Ticker) send(input tickerInput) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.WriteJSON(input)
+}
+
+// readMessages reads messages until the connection fails or the context is cancelled
+func (t *Ticker) readMessages(ctx context.Context) {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		if t.conn == conn {
+			t.conn = nil
+		}
+		t.mu.Unlock()
+		conn.Close()
+	}()
+
+	// Close the connection when the context is cancelled to unblock the read.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		// The server sends heartbeats, so a silent connection is a dead one.
+		conn.SetReadDeadline(time.Now().Add(2 * dataTimeoutInterval))
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				t.triggerClose(websocket.CloseNormalClosure, "")
+				return
+			}
+			if closeErr, ok := err.(*websocket.CloseError); ok {
+				t.triggerClose(closeErr.Code, closeErr.Text)
+			} else {
+				t.triggerError(fmt.Errorf("read failed: %w", err))
+			}
+			return
+		}
+
+		if t.callbacks.onMessage != nil {
+			t.callbacks.onMessage(messageType, message)
+		}
+
+		switch messageType {
+		case websocket.BinaryMessage:
+			ticks, err := ParseTicks(message)
+			if err != nil {
+				t.triggerError(err)
+				continue
+			}
+			for _, tick := range ticks {
+				if !t.triggerTick(ctx, tick) {
+					t.triggerClose(websocket.CloseNormalClosure, "")
+					return
+				}
+			}
+		case websocket.TextMessage:
+			t.processTextMessage(message)
+		}
+	}
+}
+
+// processTextMessage handles order updates and errors sent as text
+func (t *Ticker) processTextMessage(message []byte) {
+	var msg textMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		t.triggerError(fmt.Errorf("invalid text message: %w", err))
+		return
+	}
+	switch msg.Type {
+	case messageOrder:
+		if t.callbacks.onOrderUpdate != nil {
+			t.callbacks.onOrderUpdate(msg.Data)
+		}
+	case messageError:
+		var text string
+		if err := json.Unmarshal(msg.Data, &text); err != nil {
+			text = string(msg.Data)
+		}
+		t.triggerError(fmt.Errorf("%s", text))
+	}
+}
+
+// reconnectDelay returns the exponential backoff delay of the attempt
+func (t *Ticker) reconnectDelay(attempt int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempt-1))) * time.Second
+	if delay > t.reconnectMaxDelay || delay <= 0 {
+		delay = t.reconnectMaxDelay
+	}
+	return delay
+}
+
+// triggerTick delivers the tick, it returns false if the context was
+// cancelled while waiting for a tick channel
+func (t *Ticker) triggerTick(ctx context.Context, tick Tick) bool {
+	if t.callbacks.onTick != nil {
+		t.callbacks.onTick(tick)
+	}
+	for _, ch := range t.tickChans {
+		select {
+		case ch <- tick:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Ticker) triggerError(err error) {
+	if t.callbacks.onError != nil {
+		t.callbacks.onError(err)
+	}
+}
+
+func (t *Ticker) triggerConnect() {
+	if t.callbacks.onConnect != nil {
+		t.callbacks.onConnect()
+	}
+}
+
+func (t *Ticker) triggerClose(code int, reason string) {
+	if t.callbacks.onClose != nil {
+		t.callbacks.onClose(code, reason)
+	}
+}
+
+func (t *Ticker) triggerReconnect(attempt int, delay time.Duration) {
+	if t.callbacks.onReconnect != nil {
+		t.callbacks.onReconnect(attempt, delay)
+	}
+}
+
+func (t *Ticker) triggerNoReconnect(attempt int) {
+	if t.callbacks.onNoReconnect != nil {
+		t.callbacks.onNoReconnect(attempt)
+	}
+}
+
+func (t *Ticker) closeTickChans() {
+	for _, ch := range t.tickChans {
+		close(ch)
+	}
+	t.tickChans = nil
+}
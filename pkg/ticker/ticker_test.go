@@ -0,0 +1,172 @@
+package mbticker_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+	"github.com/nsvirk/gomoneybotslib/pkg/ticker/tickertest"
+)
+
+// waitFor polls the condition until it holds or the timeout expires
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// startTicker serves a ticker against the server and waits for it to connect
+func startTicker(t *testing.T, server *tickertest.Server, ticker *mbticker.Ticker) (connected chan struct{}, done chan struct{}) {
+	t.Helper()
+	connected = make(chan struct{}, 10)
+	done = make(chan struct{})
+	ticker.SetRootURL(server.URL())
+	ticker.OnConnect(func() { connected <- struct{}{} })
+	go func() {
+		ticker.Serve()
+		close(done)
+	}()
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for connection")
+	}
+	return connected, done
+}
+
+func TestTickerSubscribeAndMode(t *testing.T) {
+	server := tickertest.NewServer("enctoken")
+	defer server.Close()
+	ticker := mbticker.NewWithCredentials("AB1234", "enctoken")
+	ticks := ticker.Ticks(10)
+	_, done := startTicker(t, server, ticker)
+	defer func() {
+		ticker.Stop()
+		<-done
+	}()
+
+	if err := ticker.Subscribe([]uint32{779521, 256265}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := ticker.SetMode(mbticker.ModeFull, []uint32{779521}); err != nil {
+		t.Fatalf("SetMode() error = %v", err)
+	}
+	want := map[uint32]mbticker.Mode{779521: mbticker.ModeFull, 256265: mbticker.ModeQuote}
+	waitFor(t, 5*time.Second, "server subscriptions", func() bool {
+		return reflect.DeepEqual(server.Subscriptions(), want)
+	})
+	if got := ticker.Subscriptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Subscriptions() = %v, want %v", got, want)
+	}
+	if err := ticker.SetMode("depth", []uint32{779521}); err == nil {
+		t.Error("SetMode() with an invalid mode succeeded")
+	}
+
+	sent := mbticker.Tick{Mode: mbticker.ModeLTP, InstrumentToken: 779521, IsTradable: true, LastPrice: 812.35}
+	server.SendTicks([]mbticker.Tick{sent})
+	select {
+	case got := <-ticks:
+		if !reflect.DeepEqual(got, sent) {
+			t.Errorf("got tick %+v, want %+v", got, sent)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for tick")
+	}
+
+	if err := ticker.Unsubscribe([]uint32{256265}); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	want = map[uint32]mbticker.Mode{779521: mbticker.ModeFull}
+	waitFor(t, 5*time.Second, "unsubscribe", func() bool {
+		return reflect.DeepEqual(server.Subscriptions(), want)
+	})
+}
+
+func TestTickerReconnectResubscribes(t *testing.T) {
+	server := tickertest.NewServer("")
+	defer server.Close()
+	ticker := mbticker.NewWithCredentials("AB1234", "enctoken")
+	reconnects := make(chan int, 10)
+	ticker.OnReconnect(func(attempt int, delay time.Duration) { reconnects <- attempt })
+	connected, done := startTicker(t, server, ticker)
+	defer func() {
+		ticker.Stop()
+		<-done
+	}()
+
+	if err := ticker.Subscribe([]uint32{779521, 256265}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := ticker.SetMode(mbticker.ModeLTP, []uint32{256265}); err != nil {
+		t.Fatalf("SetMode() error = %v", err)
+	}
+	want := map[uint32]mbticker.Mode{779521: mbticker.ModeQuote, 256265: mbticker.ModeLTP}
+	waitFor(t, 5*time.Second, "server subscriptions", func() bool {
+		return reflect.DeepEqual(server.Subscriptions(), want)
+	})
+
+	server.DropConnections()
+	select {
+	case attempt := <-reconnects:
+		if attempt != 1 {
+			t.Errorf("first reconnect attempt = %d, want 1", attempt)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnect")
+	}
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnection")
+	}
+	waitFor(t, 5*time.Second, "resubscription", func() bool {
+		return reflect.DeepEqual(server.Subscriptions(), want)
+	})
+}
+
+func TestTickerStop(t *testing.T) {
+	server := tickertest.NewServer("")
+	defer server.Close()
+	ticker := mbticker.NewWithCredentials("AB1234", "enctoken")
+	ticks := ticker.Ticks(0)
+	_, done := startTicker(t, server, ticker)
+
+	// A tick nobody receives must not keep Stop from ending Serve.
+	server.SendTicks([]mbticker.Tick{{Mode: mbticker.ModeLTP, InstrumentToken: 779521, LastPrice: 1}})
+	time.Sleep(50 * time.Millisecond)
+
+	ticker.Stop()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after Stop")
+	}
+	for range ticks {
+	}
+}
+
+func TestTickerStopBeforeServe(t *testing.T) {
+	ticker := mbticker.NewWithCredentials("AB1234", "enctoken")
+	ticks := ticker.Ticks(1)
+	ticker.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		ticker.Serve()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after an earlier Stop")
+	}
+	if _, ok := <-ticks; ok {
+		t.Error("tick channel is open after Serve returned")
+	}
+}
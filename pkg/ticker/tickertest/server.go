@@ -0,0 +1,202 @@
+package tickertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	mbticker "github.com/nsvirk/gomoneybotslib/pkg/ticker"
+)
+
+// heartbeatInterval is the interval between heartbeats sent to clients
+const heartbeatInterval = time.Second
+
+// Server is a local WebSocket server that speaks the ticker protocol, for
+// developing and testing bots without a live market data connection
+type Server struct {
+	httpServer *httptest.Server
+	upgrader   websocket.Upgrader
+	enctoken   string
+
+	mu            sync.Mutex
+	conns         map[*websocket.Conn]*sync.Mutex
+	subscriptions map[uint32]mbticker.Mode
+}
+
+// input is a message sent by a ticker client
+type input struct {
+	Action string          `json:"a"`
+	Value  json.RawMessage `json:"v"`
+}
+
+// NewServer starts a new test server. Connections must present the enctoken,
+// an empty enctoken accepts any connection.
+func NewServer(enctoken string) *Server {
+	s := &Server{
+		enctoken:      enctoken,
+		conns:         make(map[*websocket.Conn]*sync.Mutex),
+		subscriptions: make(map[uint32]mbticker.Mode),
+	}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL returns the WebSocket url of the server, to be passed to `Ticker.SetRootURL`
+func (s *Server) URL() url.URL {
+	u, _ := url.Parse("ws" + strings.TrimPrefix(s.httpServer.URL, "http"))
+	return *u
+}
+
+// Subscriptions returns the tokens subscribed by clients and their modes
+func (s *Server) Subscriptions() map[uint32]mbticker.Mode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscriptions := make(map[uint32]mbticker.Mode, len(s.subscriptions))
+	for token, mode := range s.subscriptions {
+		subscriptions[token] = mode
+	}
+	return subscriptions
+}
+
+// SendTicks sends the ticks to every connected client as a binary message
+func (s *Server) SendTicks(ticks []mbticker.Tick) {
+	s.broadcast(websocket.BinaryMessage, mbticker.MarshalTicks(ticks))
+}
+
+// SendText sends a text message, e.g. an order update or error, to every connected client
+func (s *Server) SendText(messageType string, data interface{}) error {
+	message, err := json.Marshal(map[string]interface{}{"type": messageType, "data": data})
+	if err != nil {
+		return err
+	}
+	s.broadcast(websocket.TextMessage, message)
+	return nil
+}
+
+// DropConnections closes every client connection and forgets their
+// subscriptions, to exercise reconnection and resubscription
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+		delete(s.conns, conn)
+	}
+	s.subscriptions = make(map[uint32]mbticker.Mode)
+}
+
+// Close closes every connection and shuts the server down
+func (s *Server) Close() {
+	s.DropConnections()
+	s.httpServer.Close()
+}
+
+// handle upgrades a connection and processes its messages
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if s.enctoken != "" && r.URL.Query().Get("enctoken") != s.enctoken {
+		http.Error(w, "invalid enctoken", http.StatusForbidden)
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	writeMu := &sync.Mutex{}
+	s.mu.Lock()
+	s.conns[conn] = writeMu
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.heartbeat(conn, writeMu, done)
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.processInput(message)
+	}
+}
+
+// processInput records subscription changes
+func (s *Server) processInput(message []byte) {
+	var in input
+	if err := json.Unmarshal(message, &in); err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch in.Action {
+	case "subscribe":
+		var tokens []uint32
+		if json.Unmarshal(in.Value, &tokens) == nil {
+			for _, token := range tokens {
+				if _, ok := s.subscriptions[token]; !ok {
+					s.subscriptions[token] = mbticker.ModeQuote
+				}
+			}
+		}
+	case "unsubscribe":
+		var tokens []uint32
+		if json.Unmarshal(in.Value, &tokens) == nil {
+			for _, token := range tokens {
+				delete(s.subscriptions, token)
+			}
+		}
+	case "mode":
+		var value []json.RawMessage
+		if json.Unmarshal(in.Value, &value) != nil || len(value) != 2 {
+			return
+		}
+		var (
+			mode   mbticker.Mode
+			tokens []uint32
+		)
+		if json.Unmarshal(value[0], &mode) == nil && json.Unmarshal(value[1], &tokens) == nil {
+			for _, token := range tokens {
+				s.subscriptions[token] = mode
+			}
+		}
+	}
+}
+
+// heartbeat sends a 1 byte binary message every interval
+func (s *Server) heartbeat(conn *websocket.Conn, writeMu *sync.Mutex, done chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			writeMu.Lock()
+			err := conn.WriteMessage(websocket.BinaryMessage, []byte{0})
+			writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// broadcast writes the message to every connected client
+func (s *Server) broadcast(messageType int, message []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn, writeMu := range s.conns {
+		writeMu.Lock()
+		conn.WriteMessage(messageType, message)
+		writeMu.Unlock()
+	}
+}